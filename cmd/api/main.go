@@ -1,18 +1,20 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/qullDev/book_API/internal/cache"
 	"github.com/qullDev/book_API/internal/config"
 	"github.com/qullDev/book_API/internal/db"
-	"github.com/qullDev/book_API/internal/domain/book"
-	"github.com/qullDev/book_API/internal/domain/category"
 	"github.com/qullDev/book_API/internal/domain/user"
 	"github.com/qullDev/book_API/internal/http/router"
 	appauth "github.com/qullDev/book_API/internal/pkg/auth"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/qullDev/book_API/internal/pkg/notify"
+	"github.com/qullDev/book_API/internal/pkg/password"
+	"github.com/qullDev/book_API/internal/pkg/secret"
 
 	_ "github.com/qullDev/book_API/docs" // swagger docs
 )
@@ -34,6 +36,14 @@ import (
 // @tag.description Book operations
 // @tag.name categories
 // @tag.description Category operations
+// @tag.name authors
+// @tag.description Author operations
+// @tag.name audit
+// @tag.description Audit trail
+// @tag.name admin
+// @tag.description User administration (admin only)
+// @tag.name keys
+// @tag.description Public keys for verifying tokens
 func main() {
 	// Load config
 	cfg, err := config.Load()
@@ -58,24 +68,69 @@ func main() {
 		log.Fatal("Error connecting to redis:", err)
 	}
 	ts := appauth.NewTokenStore(rdb)
+	// sesi yang dibuat sebelum index sesi per user ada
+	if err := ts.IndexSessions(context.Background()); err != nil {
+		log.Fatal("Error indexing sessions:", err)
+	}
+	guard := appauth.NewLoginGuard(rdb, appauth.LoginGuardConfig{
+		MaxUserAttempts: cfg.LoginMaxAttempts,
+		MaxIPAttempts:   cfg.LoginIPMaxAttempts,
+		Window:          cfg.LoginAttemptWindow,
+		BaseDelay:       cfg.LoginBackoffBase,
+		LockoutDuration: cfg.LoginLockoutDuration,
+	})
+
+	// KEK untuk data rahasia di database (private key JWT, secret MFA)
+	box, err := secret.New(cfg.KeyEncryptionKey)
+	if err != nil {
+		log.Fatal("Error configuring key encryption key:", err)
+	}
 
-	// AutoMigrate
-	if err := dbConn.AutoMigrate(&user.User{}, &category.Category{}, &book.Book{}); err != nil {
+	// Migrasi skema (AutoMigrate + migration SQL)
+	if err := db.Migrate(dbConn); err != nil {
 		log.Fatal("Error migrating database:", err)
 	}
 	log.Println("✅ Database migrated")
+	if err := db.SealSecrets(dbConn, box); err != nil {
+		log.Fatal("Error encrypting stored secrets:", err)
+	}
+
+	// key tanda tangan JWT; rotasi dicek berkala di background
+	keys, err := appauth.NewKeyRing(dbConn, cfg, box)
+	if err != nil {
+		log.Fatal("Error configuring JWT signing keys:", err)
+	}
+	go keys.Run(context.Background(), time.Minute)
 
-	// SEED user
+	passwords, err := password.New(cfg)
+	if err != nil {
+		log.Fatal("Error configuring password hasher:", err)
+	}
+
+	// SEED user; password awal wajib diganti saat login pertama
 	var count int64
 	dbConn.Model(&user.User{}).Count(&count)
 	if count == 0 {
-		hashed, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+		hashed, err := passwords.Hash(cfg.AdminInitialPassword)
+		if err != nil {
+			log.Fatal("Error hashing seed password:", err)
+		}
 		dbConn.Create(&user.User{
-			Username: "admin",
-			Password: string(hashed),
+			Username:              "admin",
+			Password:              hashed,
+			Role:                  user.RoleAdmin,
+			PasswordResetRequired: true,
 		})
 	}
-	r := router.New(dbConn, cfg, ts)
+	notifier, err := notify.New(cfg)
+	if err != nil {
+		log.Fatal("Error configuring notifier:", err)
+	}
+
+	r, err := router.New(dbConn, cfg, ts, notifier, passwords, guard, keys, box)
+	if err != nil {
+		log.Fatal("Error configuring router:", err)
+	}
 	log.Println("Server is running on port:", cfg.AppPort)
 
 	// Update to use PORT env var from Railway
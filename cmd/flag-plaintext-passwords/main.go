@@ -0,0 +1,80 @@
+// Command flag-plaintext-passwords mencari user yang kolom password-nya bukan hash yang dikenali
+// (sisa password plaintext lama), mengosongkan nilainya dan menandai user wajib reset password.
+// Dijalankan sekali setelah deploy:
+//
+//	go run ./cmd/flag-plaintext-passwords -dry-run
+//	go run ./cmd/flag-plaintext-passwords
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/qullDev/book_API/internal/config"
+	"github.com/qullDev/book_API/internal/db"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"github.com/qullDev/book_API/internal/pkg/password"
+	"gorm.io/gorm"
+)
+
+// unusablePassword nilai pengganti yang tidak akan pernah cocok dengan hash apa pun
+const unusablePassword = "!legacy-plaintext"
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "hanya tampilkan user yang terdeteksi, tanpa mengubah data")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Error loading config:", err)
+	}
+	dbConn, err := db.Connect(cfg)
+	if err != nil {
+		log.Fatal("Error connecting to database:", err)
+	}
+	passwords, err := password.New(cfg)
+	if err != nil {
+		log.Fatal("Error configuring password hasher:", err)
+	}
+
+	var users []user.User
+	if err := dbConn.Select("id", "username", "password").Where("password <> ?", unusablePassword).Find(&users).Error; err != nil {
+		log.Fatal("Error reading users:", err)
+	}
+
+	flagged := 0
+	for _, u := range users {
+		if passwords.IsHash(u.Password) {
+			continue
+		}
+		flagged++
+		if *dryRun {
+			log.Printf("plaintext: %s (%s)", u.Username, u.ID)
+			continue
+		}
+		err := dbConn.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user.User{}).Where("id = ?", u.ID).Updates(map[string]interface{}{
+				"password":                unusablePassword,
+				"password_reset_required": true,
+			}).Error; err != nil {
+				return err
+			}
+			after, err := audit.Snapshot(map[string]interface{}{"password_reset_required": true, "reason": "legacy_plaintext"})
+			if err != nil {
+				return err
+			}
+			return tx.Create(&audit.Event{Action: audit.ActionUpdate, EntityType: "user", EntityID: u.ID.String(), After: after}).Error
+		})
+		if err != nil {
+			log.Fatalf("Error flagging user %s: %v", u.Username, err)
+		}
+		log.Printf("flagged: %s (%s)", u.Username, u.ID)
+	}
+
+	if *dryRun {
+		log.Printf("%d dari %d user masih menyimpan password plaintext", flagged, len(users))
+		return
+	}
+	log.Printf("%d user ditandai wajib reset password", flagged)
+}
@@ -1,11 +1,26 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// minSecretLen panjang minimal secret HMAC (JWT_SECRET, CURSOR_SECRET)
+	minSecretLen = 32
+	// minBcryptCost cost bcrypt terendah yang diterima, di atas bcrypt.MinCost
+	minBcryptCost = 10
 )
 
 type Config struct {
@@ -19,10 +34,64 @@ type Config struct {
 	RedisAddr       string
 	RedisPassword   string
 	RedisDB         int
-	JWTSecret       string
+	JWTSecret       string // hanya dipakai JWT_SIGNING_ALG=HS256
+	CursorSecret    string // tanda tangan HMAC cursor pagination
+	JWTIssuer       string // claim iss semua token; juga aud untuk refresh token dan token MFA
+	JWTAudience     string // claim aud access token, dicek juga oleh service lain
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
 	Env             string
+	// AllowRegistration false untuk deployment tertutup (user hanya dibuat admin)
+	AllowRegistration bool
+	// AdminInitialPassword password user admin yang dibuat saat database masih kosong
+	AdminInitialPassword string
+	// PasswordResetTTL masa berlaku token reset password
+	PasswordResetTTL time.Duration
+	// PasswordResetURL link di email reset; %s diganti token. Kosong = token dikirim apa adanya
+	PasswordResetURL string
+	// PasswordResetCooldown jeda minimal antar email reset untuk akun yang sama
+	PasswordResetCooldown time.Duration
+	// PasswordResetIPMax batas permintaan forgot password per IP per jam; 0 = tanpa batas
+	PasswordResetIPMax int
+	NotifyDriver       string // "log" (default) atau "smtp"
+	NotifyLogFile      string // dipakai driver log; kosong = tulis ke log standar
+	SMTPHost           string
+	SMTPPort           string
+	SMTPUsername       string
+	SMTPPassword       string
+	MailFrom           string
+	// PasswordHasher algoritma untuk hash baru: "bcrypt" (default) atau "argon2id"
+	PasswordHasher string
+	BcryptCost     int
+	Argon2Memory   uint32 // KiB
+	Argon2Time     uint32
+	Argon2Threads  uint8
+	// batas login gagal: per username, per IP, jendela hitung, jeda awal backoff dan lama kunci
+	LoginMaxAttempts     int
+	LoginIPMaxAttempts   int
+	LoginAttemptWindow   time.Duration
+	LoginBackoffBase     time.Duration
+	LoginLockoutDuration time.Duration
+	// MFAIssuer nama aplikasi di authenticator; MFATokenTTL masa berlaku token challenge MFA
+	MFAIssuer   string
+	MFATokenTTL time.Duration
+	// JWTSigningAlg algoritma access/refresh token: "EdDSA" (default), "RS256" atau "HS256" (JWTSecret).
+	// Key RS256/EdDSA dibuat dan dirotasi otomatis setiap JWTKeyRotation; key baru dipublikasikan di JWKS
+	// JWTKeyPrepublish sebelum dipakai, key lama tetap diterima JWTKeyOverlap (minimal RefreshTokenTTL).
+	JWTSigningAlg    string
+	JWTKeyRotation   time.Duration
+	JWTKeyPrepublish time.Duration
+	JWTKeyOverlap    time.Duration
+	// KeyEncryptionKey KEK 32 byte (JWT_KEY_ENCRYPTION_KEY, base64 atau hex) untuk mengenkripsi
+	// private key JWT dan secret MFA di database
+	KeyEncryptionKey []byte
+	// DenylistCacheTTL lama hasil cek denylist access token disimpan di memori; 0 = selalu cek Redis
+	DenylistCacheTTL time.Duration
+	// OAuthCodeTTL masa berlaku authorization code OAuth2
+	OAuthCodeTTL time.Duration
+	// TrustedProxies IP/CIDR reverse proxy yang boleh mengisi X-Forwarded-For (TRUSTED_PROXIES,
+	// dipisah koma). Kosong = header diabaikan dan IP klien diambil dari koneksi langsung
+	TrustedProxies []string
 }
 
 func Load() (*Config, error) {
@@ -43,22 +112,151 @@ func Load() (*Config, error) {
 		rt = 168 * time.Hour
 	}
 
+	allowRegistration, err := strconv.ParseBool(getenv("ALLOW_REGISTRATION", "true"))
+	if err != nil {
+		allowRegistration = true
+	}
+
+	resetTTL, err := time.ParseDuration(getenv("PASSWORD_RESET_TTL", "30m"))
+	if err != nil {
+		resetTTL = 30 * time.Minute
+	}
+	resetCooldown, err := time.ParseDuration(getenv("PASSWORD_RESET_COOLDOWN", "2m"))
+	if err != nil {
+		resetCooldown = 2 * time.Minute
+	}
+	resetIPMax, err := strconv.Atoi(getenv("PASSWORD_RESET_IP_MAX", "10"))
+	if err != nil {
+		resetIPMax = 10
+	}
+
+	// cost di bawah 10 terlalu cepat untuk ditebak offline, di atas bcrypt.MaxCost ditolak library
+	bcryptCost, err := strconv.Atoi(getenv("BCRYPT_COST", "12"))
+	if err != nil || bcryptCost < minBcryptCost || bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("BCRYPT_COST harus angka %d-%d", minBcryptCost, bcrypt.MaxCost)
+	}
+	argonMemory, _ := strconv.ParseUint(getenv("ARGON2_MEMORY_KB", "65536"), 10, 32)
+	argonTime, _ := strconv.ParseUint(getenv("ARGON2_TIME", "3"), 10, 32)
+	argonThreads, _ := strconv.ParseUint(getenv("ARGON2_THREADS", "2"), 10, 8)
+
+	loginMax, _ := strconv.Atoi(getenv("LOGIN_MAX_ATTEMPTS", "5"))
+	loginIPMax, _ := strconv.Atoi(getenv("LOGIN_IP_MAX_ATTEMPTS", "20"))
+	loginWindow, err := time.ParseDuration(getenv("LOGIN_ATTEMPT_WINDOW", "15m"))
+	if err != nil {
+		loginWindow = 15 * time.Minute
+	}
+	loginBackoff, err := time.ParseDuration(getenv("LOGIN_BACKOFF_BASE", "1s"))
+	if err != nil {
+		loginBackoff = time.Second
+	}
+	loginLockout, err := time.ParseDuration(getenv("LOGIN_LOCKOUT_DURATION", "15m"))
+	if err != nil {
+		loginLockout = 15 * time.Minute
+	}
+
+	mfaTTL, err := time.ParseDuration(getenv("MFA_TOKEN_TTL", "5m"))
+	if err != nil {
+		mfaTTL = 5 * time.Minute
+	}
+
+	keyRotation, err := time.ParseDuration(getenv("JWT_KEY_ROTATION", "720h"))
+	if err != nil {
+		keyRotation = 720 * time.Hour
+	}
+	keyPrepublish, err := time.ParseDuration(getenv("JWT_KEY_PREPUBLISH", "24h"))
+	if err != nil {
+		keyPrepublish = 24 * time.Hour
+	}
+	keyOverlap, err := time.ParseDuration(getenv("JWT_KEY_OVERLAP", "192h"))
+	if err != nil {
+		keyOverlap = 192 * time.Hour
+	}
+
+	denyCacheTTL, err := time.ParseDuration(getenv("DENYLIST_CACHE_TTL", "5s"))
+	if err != nil {
+		denyCacheTTL = 5 * time.Second
+	}
+
+	oauthCodeTTL, err := time.ParseDuration(getenv("OAUTH_CODE_TTL", "1m"))
+	if err != nil {
+		oauthCodeTTL = time.Minute
+	}
+
+	var trustedProxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			trustedProxies = append(trustedProxies, p)
+		}
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	signingAlg := getenv("JWT_SIGNING_ALG", "EdDSA")
+	// secret lemah di mode HS256 berarti siapa pun bisa memalsukan token
+	if signingAlg == "HS256" && (len(jwtSecret) < minSecretLen || jwtSecret == "your-secret-key") {
+		return nil, errors.New("JWT_SECRET wajib diisi minimal 32 byte acak untuk JWT_SIGNING_ALG=HS256")
+	}
+
+	kek, err := decodeKey(os.Getenv("JWT_KEY_ENCRYPTION_KEY"))
+	if err != nil {
+		return nil, err
+	}
+	cursorSecret, err := cursorSecret(os.Getenv("CURSOR_SECRET"), kek)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update defaults for Railway
 	return &Config{
-		AppPort:         port,
-		DBHost:          getenv("DB_HOST", "localhost"),
-		DBPort:          getenv("DB_PORT", "5432"),
-		DBUser:          getenv("DB_USER", "postgres"),
-		DBPassword:      getenv("DB_PASSWORD", ""),
-		DBName:          getenv("DB_NAME", "railway"),
-		DBSSLMode:       getenv("DB_SSLMODE", "require"), // Change default to require
-		RedisAddr:       getenv("REDIS_ADDR", "localhost:6379"),
-		RedisPassword:   getenv("REDIS_PASSWORD", ""),
-		RedisDB:         redisDB,
-		JWTSecret:       getenv("JWT_SECRET", "your-secret-key"),
-		AccessTokenTTL:  at,
-		RefreshTokenTTL: rt,
-		Env:             getenv("ENV", "production"), // Change default to production
+		AppPort:               port,
+		DBHost:                getenv("DB_HOST", "localhost"),
+		DBPort:                getenv("DB_PORT", "5432"),
+		DBUser:                getenv("DB_USER", "postgres"),
+		DBPassword:            getenv("DB_PASSWORD", ""),
+		DBName:                getenv("DB_NAME", "railway"),
+		DBSSLMode:             getenv("DB_SSLMODE", "require"), // Change default to require
+		RedisAddr:             getenv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword:         getenv("REDIS_PASSWORD", ""),
+		RedisDB:               redisDB,
+		JWTSecret:             jwtSecret,
+		CursorSecret:          cursorSecret,
+		JWTIssuer:             getenv("JWT_ISSUER", "book-api"),
+		JWTAudience:           getenv("JWT_AUDIENCE", "book-api:resources"),
+		AccessTokenTTL:        at,
+		RefreshTokenTTL:       rt,
+		Env:                   getenv("ENV", "production"), // Change default to production
+		AllowRegistration:     allowRegistration,
+		AdminInitialPassword:  getenv("ADMIN_INITIAL_PASSWORD", "password"),
+		PasswordResetTTL:      resetTTL,
+		PasswordResetURL:      getenv("PASSWORD_RESET_URL", ""),
+		PasswordResetCooldown: resetCooldown,
+		PasswordResetIPMax:    resetIPMax,
+		NotifyDriver:          getenv("NOTIFY_DRIVER", "log"),
+		NotifyLogFile:         getenv("NOTIFY_LOG_FILE", ""),
+		SMTPHost:              getenv("SMTP_HOST", ""),
+		SMTPPort:              getenv("SMTP_PORT", "587"),
+		SMTPUsername:          getenv("SMTP_USERNAME", ""),
+		SMTPPassword:          getenv("SMTP_PASSWORD", ""),
+		MailFrom:              getenv("MAIL_FROM", ""),
+		PasswordHasher:        getenv("PASSWORD_HASHER", "bcrypt"),
+		BcryptCost:            bcryptCost,
+		Argon2Memory:          uint32(argonMemory),
+		Argon2Time:            uint32(argonTime),
+		Argon2Threads:         uint8(argonThreads),
+		LoginMaxAttempts:      loginMax,
+		LoginIPMaxAttempts:    loginIPMax,
+		LoginAttemptWindow:    loginWindow,
+		LoginBackoffBase:      loginBackoff,
+		LoginLockoutDuration:  loginLockout,
+		MFAIssuer:             getenv("MFA_ISSUER", "Book API"),
+		MFATokenTTL:           mfaTTL,
+		JWTSigningAlg:         signingAlg,
+		JWTKeyRotation:        keyRotation,
+		JWTKeyPrepublish:      keyPrepublish,
+		JWTKeyOverlap:         keyOverlap,
+		KeyEncryptionKey:      kek,
+		DenylistCacheTTL:      denyCacheTTL,
+		OAuthCodeTTL:          oauthCodeTTL,
+		TrustedProxies:        trustedProxies,
 	}, nil
 }
 
@@ -68,3 +266,32 @@ func getenv(k, def string) string {
 	}
 	return def
 }
+
+// decodeKey terima KEK 32 byte dalam hex (64 karakter) atau base64
+func decodeKey(raw string) ([]byte, error) {
+	if raw == "" {
+		return nil, errors.New("JWT_KEY_ENCRYPTION_KEY wajib diisi (32 byte, base64 atau hex)")
+	}
+	key, err := hex.DecodeString(raw)
+	if err != nil {
+		key, err = base64.StdEncoding.DecodeString(raw)
+	}
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("JWT_KEY_ENCRYPTION_KEY harus 32 byte dalam base64 atau hex")
+	}
+	return key, nil
+}
+
+// cursorSecret pakai CURSOR_SECRET jika diisi; jika tidak, diturunkan dari KEK
+// sehingga tidak perlu konfigurasi tambahan dan tidak sama dengan key lain
+func cursorSecret(raw string, kek []byte) (string, error) {
+	if raw != "" {
+		if len(raw) < minSecretLen {
+			return "", fmt.Errorf("CURSOR_SECRET minimal %d byte", minSecretLen)
+		}
+		return raw, nil
+	}
+	mac := hmac.New(sha256.New, kek)
+	mac.Write([]byte("book-api cursor v1"))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCursorSecret(t *testing.T) {
+	kek := bytes.Repeat([]byte{1}, 32)
+	derived, err := cursorSecret("", kek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := cursorSecret("", kek); again != derived {
+		t.Fatal("derived secret is not stable")
+	}
+	if other, _ := cursorSecret("", bytes.Repeat([]byte{2}, 32)); other == derived {
+		t.Fatal("derived secret does not depend on the KEK")
+	}
+	if len(derived) < minSecretLen || strings.Contains(derived, string(kek)) {
+		t.Fatalf("unexpected derived secret %q", derived)
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{"explicit", strings.Repeat("s", 32), false},
+		{"too short", strings.Repeat("s", 31), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cursorSecret(tt.raw, kek)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.raw {
+				t.Fatalf("cursorSecret = %q, want %q", got, tt.raw)
+			}
+		})
+	}
+}
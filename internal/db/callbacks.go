@@ -0,0 +1,38 @@
+package db
+
+import (
+	"github.com/qullDev/book_API/internal/pkg/actor"
+	"gorm.io/gorm"
+)
+
+// registerCallbacks mengisi kolom CreatedBy/ModifiedBy semua model dari user yang ada di context query.
+// Handler cukup memakai db.WithContext(c.Request.Context()).
+func registerCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("app:created_by", setCreatedBy); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").Register("app:modified_by", setModifiedBy)
+}
+
+func setCreatedBy(tx *gorm.DB) {
+	setActorColumns(tx, "CreatedBy", "ModifiedBy")
+}
+
+func setModifiedBy(tx *gorm.DB) {
+	setActorColumns(tx, "ModifiedBy")
+}
+
+func setActorColumns(tx *gorm.DB, fields ...string) {
+	if tx.Statement.Schema == nil {
+		return
+	}
+	userID, ok := actor.UserID(tx.Statement.Context)
+	if !ok {
+		return
+	}
+	for _, name := range fields {
+		if tx.Statement.Schema.LookUpField(name) != nil {
+			tx.Statement.SetColumn(name, userID, true)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/author"
+	"github.com/qullDev/book_API/internal/domain/book"
+	"github.com/qullDev/book_API/internal/domain/category"
+	"github.com/qullDev/book_API/internal/domain/oauth"
+	"github.com/qullDev/book_API/internal/domain/user"
+	appauth "github.com/qullDev/book_API/internal/pkg/auth"
+	"gorm.io/gorm"
+)
+
+// migration = perubahan skema yang tidak bisa ditangani AutoMigrate (index khusus, kolom generated, dll)
+type migration struct {
+	ID string
+	Up func(tx *gorm.DB) error
+}
+
+// schemaMigration mencatat migration yang sudah dijalankan
+type schemaMigration struct {
+	ID        string `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time
+}
+
+// migrations dijalankan berurutan; jangan ubah ID atau isi migration yang sudah rilis
+var migrations = []migration{
+	{
+		ID: "20250901_books_search_vector",
+		Up: func(tx *gorm.DB) error {
+			// kolom tsvector di-generate dari title (bobot A) dan description (bobot B)
+			if err := tx.Exec(`ALTER TABLE books ADD COLUMN IF NOT EXISTS search_vector tsvector
+				GENERATED ALWAYS AS (
+					setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+					setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+				) STORED`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN (search_vector)`).Error
+		},
+	},
+	{
+		// database baru hanya mendapat idx_categories_name_active (parsial) dari AutoMigrate, padahal
+		// 20250903_foreign_keys memakai ON CONFLICT (name) yang butuh unique index penuh.
+		// Index penuh dibuat di sini lalu dihapus lagi oleh 20250917_categories_name_active_unique.
+		ID: "20250902_categories_name_unique",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_name ON categories (name)`).Error
+		},
+	},
+	{
+		ID: "20250903_foreign_keys",
+		Up: func(tx *gorm.DB) error {
+			stmts := []string{
+				// buku yatim (kategorinya sudah terhapus) dipindah ke kategori penampung agar FK bisa dibuat
+				`INSERT INTO categories (id, name, created_at, modified_at)
+					SELECT gen_random_uuid(), 'Tanpa Kategori', now(), now()
+					WHERE EXISTS (SELECT 1 FROM books b WHERE NOT EXISTS (SELECT 1 FROM categories c WHERE c.id = b.category_id))
+					ON CONFLICT (name) DO NOTHING`,
+				`UPDATE books SET category_id = (SELECT id FROM categories WHERE name = 'Tanpa Kategori')
+					WHERE NOT EXISTS (SELECT 1 FROM categories c WHERE c.id = books.category_id)`,
+				`DELETE FROM book_authors ba
+					WHERE NOT EXISTS (SELECT 1 FROM books b WHERE b.id = ba.book_id)
+					   OR NOT EXISTS (SELECT 1 FROM authors a WHERE a.id = ba.author_id)`,
+
+				// kategori tidak bisa dihapus permanen selama masih dipakai buku (termasuk yang di trash)
+				`ALTER TABLE books DROP CONSTRAINT IF EXISTS fk_books_category`,
+				`ALTER TABLE books ADD CONSTRAINT fk_books_category FOREIGN KEY (category_id)
+					REFERENCES categories(id) ON UPDATE CASCADE ON DELETE RESTRICT`,
+				`ALTER TABLE book_authors DROP CONSTRAINT IF EXISTS fk_books_authors`,
+				`ALTER TABLE book_authors ADD CONSTRAINT fk_books_authors FOREIGN KEY (book_id)
+					REFERENCES books(id) ON DELETE CASCADE`,
+				`ALTER TABLE book_authors DROP CONSTRAINT IF EXISTS fk_book_authors_author`,
+				`ALTER TABLE book_authors ADD CONSTRAINT fk_book_authors_author FOREIGN KEY (author_id)
+					REFERENCES authors(id) ON DELETE RESTRICT`,
+			}
+			for _, stmt := range stmts {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID: "20250905_audit_events_append_only",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec(`CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+				BEGIN
+					RAISE EXCEPTION 'audit_events is append-only';
+				END;
+				$$ LANGUAGE plpgsql`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`DROP TRIGGER IF EXISTS trg_audit_events_append_only ON audit_events`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`CREATE TRIGGER trg_audit_events_append_only
+				BEFORE UPDATE OR DELETE ON audit_events
+				FOR EACH ROW EXECUTE FUNCTION audit_events_append_only()`).Error
+		},
+	},
+	{
+		ID: "20250907_book_revisions_fk",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TABLE book_revisions DROP CONSTRAINT IF EXISTS fk_book_revisions_book`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`ALTER TABLE book_revisions ADD CONSTRAINT fk_book_revisions_book FOREIGN KEY (book_id)
+				REFERENCES books(id) ON DELETE CASCADE`).Error
+		},
+	},
+	{
+		// user lama mendapat role default reader; seed admin (atau user tertua jika tidak ada) dijadikan admin
+		ID: "20250909_user_roles_admin",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec(`UPDATE users SET role = 'admin' WHERE username = 'admin'`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`UPDATE users SET role = 'admin'
+				WHERE id = (SELECT id FROM users ORDER BY created_at ASC LIMIT 1)
+				AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'admin')`).Error
+		},
+	},
+	{
+		ID: "20250911_user_recovery_codes_fk",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TABLE user_recovery_codes DROP CONSTRAINT IF EXISTS fk_user_recovery_codes_user`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`ALTER TABLE user_recovery_codes ADD CONSTRAINT fk_user_recovery_codes_user FOREIGN KEY (user_id)
+				REFERENCES users(id) ON DELETE CASCADE`).Error
+		},
+	},
+	{
+		ID: "20250913_api_keys_fk",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TABLE api_keys DROP CONSTRAINT IF EXISTS fk_api_keys_user`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`ALTER TABLE api_keys ADD CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id)
+				REFERENCES users(id) ON DELETE CASCADE`).Error
+		},
+	},
+	{
+		ID: "20250915_oauth_clients_fk",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TABLE oauth_clients DROP CONSTRAINT IF EXISTS fk_oauth_clients_user`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`ALTER TABLE oauth_clients ADD CONSTRAINT fk_oauth_clients_user FOREIGN KEY (user_id)
+				REFERENCES users(id) ON DELETE CASCADE`).Error
+		},
+	},
+	{
+		// nama kategori hanya unik di antara kategori aktif (idx_categories_name_active dari AutoMigrate);
+		// index lama juga menghitung kategori di trash sehingga nama kategori yang dihapus tidak bisa dipakai lagi
+		ID: "20250917_categories_name_active_unique",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(`DROP INDEX IF EXISTS idx_categories_name`).Error
+		},
+	},
+}
+
+// Migrate menjalankan AutoMigrate untuk semua model lalu migration SQL yang belum pernah dijalankan
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&user.User{}, &category.Category{}, &author.Author{}, &book.Book{}, &book.BookAuthor{}, &book.Revision{}, &audit.Event{}, &user.RecoveryCode{}, &user.RoleSetting{}, &appauth.SigningKey{}, &user.APIKey{}, &oauth.Client{}); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		var count int64
+		if err := db.Model(&schemaMigration{}).Where("id = ?", m.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{ID: m.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m.ID, err)
+		}
+		log.Println("applied migration:", m.ID)
+	}
+	return nil
+}
@@ -5,9 +5,6 @@ import (
 	"log"
 
 	"github.com/qullDev/book_API/internal/config"
-	"github.com/qullDev/book_API/internal/domain/book"
-	"github.com/qullDev/book_API/internal/domain/category"
-	"github.com/qullDev/book_API/internal/domain/user"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -16,15 +13,15 @@ func Connect(cfg *config.Config) (*gorm.DB, error) {
 
 	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s  password=%s sslmode=%s", cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBName, cfg.DBPassword, cfg.DBSSLMode)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// foreign key dikelola oleh migration (lihat migrate.go), bukan oleh AutoMigrate.
+	// TranslateError: pelanggaran unique index dikembalikan sebagai gorm.ErrDuplicatedKey
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true, TranslateError: true})
 	if err != nil {
 		log.Fatal("Error connecting to database:", err)
 		return nil, err
 	}
-
-	// Auto migrate schema
-	if err := db.AutoMigrate(&category.Category{}, &book.Book{}, &user.User{}); err != nil {
-		log.Println("AutoMigrate warning:", err)
+	if err := registerCallbacks(db); err != nil {
+		return nil, err
 	}
 
 	return db, nil
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"log"
+
+	"github.com/qullDev/book_API/internal/domain/user"
+	"github.com/qullDev/book_API/internal/pkg/secret"
+	"gorm.io/gorm"
+)
+
+// SealSecrets enkripsi secret MFA yang masih tersimpan plaintext dari versi lama.
+// Aman dijalankan setiap startup: nilai yang sudah terenkripsi dilewati.
+func SealSecrets(db *gorm.DB, box *secret.Box) error {
+	var users []user.User
+	if err := db.Select("id", "mfa_secret", "mfa_pending_secret").
+		Where("(mfa_secret <> '' AND mfa_secret NOT LIKE 'enc:%') OR (mfa_pending_secret <> '' AND mfa_pending_secret NOT LIKE 'enc:%')").
+		Find(&users).Error; err != nil {
+		return err
+	}
+	for _, u := range users {
+		cols := map[string]interface{}{}
+		for col, val := range map[string]string{"mfa_secret": u.MFASecret, "mfa_pending_secret": u.MFAPendingSecret} {
+			if val == "" || secret.Sealed(val) {
+				continue
+			}
+			sealed, err := box.Seal(val)
+			if err != nil {
+				return err
+			}
+			cols[col] = sealed
+		}
+		// UpdateColumns: bukan perubahan oleh user, modified_at/modified_by tidak disentuh
+		if err := db.Model(&user.User{}).Where("id = ?", u.ID).UpdateColumns(cols).Error; err != nil {
+			return err
+		}
+	}
+	if len(users) > 0 {
+		log.Printf("secret MFA %d user dienkripsi", len(users))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// JSON = nilai jsonb mentah
+type JSON json.RawMessage
+
+func (JSON) GormDataType() string {
+	return "jsonb"
+}
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return fmt.Errorf("audit.JSON: tipe tidak didukung %T", src)
+	}
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(b []byte) error {
+	*j = append((*j)[:0], b...)
+	return nil
+}
+
+// Snapshot serialisasi v menjadi JSON; nil menghasilkan JSON kosong (NULL di database)
+func Snapshot(v interface{}) (JSON, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JSON(b), nil
+}
+
+// Change perubahan satu field antara dua snapshot
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Diff bandingkan field level atas dua snapshot object JSON
+func Diff(before, after JSON) (map[string]Change, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string]Change{}
+	for k, bv := range b {
+		if av, ok := a[k]; !ok || !reflect.DeepEqual(bv, av) {
+			changes[k] = Change{From: bv, To: a[k]}
+		}
+	}
+	for k, av := range a {
+		if _, ok := b[k]; !ok {
+			changes[k] = Change{From: nil, To: av}
+		}
+	}
+	return changes, nil
+}
+
+func toMap(j JSON) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if len(j) == 0 {
+		return m, nil
+	}
+	if err := json.Unmarshal(j, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"gorm.io/gorm"
+)
+
+// Action yang dicatat di audit log
+const (
+	ActionCreate      = "create"
+	ActionUpdate      = "update"
+	ActionDelete      = "delete"
+	ActionRestore     = "restore"
+	ActionPurge       = "purge"
+	ActionLogin       = "login"
+	ActionLoginFailed = "login_failed"
+	ActionRefresh     = "refresh"
+	ActionLogout      = "logout"
+	ActionRollback    = "rollback"
+	ActionRegister    = "register"
+	ActionPassword    = "password_change"
+	ActionResetReq    = "password_reset_request"
+	ActionLockout     = "login_lockout"
+	ActionUnlock      = "unlock"
+	ActionMFAEnable   = "mfa_enable"
+	ActionMFADisable  = "mfa_disable"
+	ActionMFARecovery = "mfa_recovery_code_used"
+	ActionTokenReuse  = "refresh_token_reuse"
+	ActionAuthorize   = "oauth_authorize"
+)
+
+// Event = satu baris audit_events; tabel ini append-only (dijaga trigger di migration)
+type Event struct {
+	ID         uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey"`
+	ActorID    *uuid.UUID    `json:"actor_id" gorm:"type:uuid;index"`
+	Actor      *user.Summary `json:"actor" gorm:"foreignKey:ActorID"`
+	Action     string        `json:"action" gorm:"size:50;not null;index"`
+	EntityType string        `json:"entity_type" gorm:"size:50;not null;index:idx_audit_events_entity"`
+	EntityID   string        `json:"entity_id" gorm:"size:64;index:idx_audit_events_entity"`
+	Before     JSON          `json:"before" swaggertype:"object"`
+	After      JSON          `json:"after" swaggertype:"object"`
+	Changes    JSON          `json:"changes" swaggertype:"object"` // {"field": {"from": x, "to": y}}
+	RequestID  string        `json:"request_id" gorm:"size:64;index"`
+	ClientIP   string        `json:"client_ip" gorm:"size:64"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"index"`
+}
+
+func (Event) TableName() string {
+	return "audit_events"
+}
+
+func (e *Event) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return
+}
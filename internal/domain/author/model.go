@@ -0,0 +1,26 @@
+package author
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"gorm.io/gorm"
+)
+
+type Author struct {
+	ID         uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey"`
+	Name       string        `json:"name" gorm:"size:150;not null;index"`
+	Bio        string        `json:"bio" gorm:"type:text"`
+	CreatedAt  time.Time     `json:"created_at"`
+	CreatedBy  uuid.UUID     `json:"created_by_id" gorm:"type:uuid"`
+	ModifiedAt time.Time     `json:"modified_at" gorm:"autoUpdateTime"`
+	ModifiedBy uuid.UUID     `json:"modified_by_id" gorm:"type:uuid"`
+	Creator    *user.Summary `json:"created_by" gorm:"foreignKey:CreatedBy"`
+	Modifier   *user.Summary `json:"modified_by" gorm:"foreignKey:ModifiedBy"`
+}
+
+func (a *Author) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
@@ -0,0 +1,27 @@
+package book
+
+import (
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/author"
+)
+
+// Peran author pada sebuah buku
+const (
+	RolePrimary    = "primary"
+	RoleCoAuthor   = "co_author"
+	RoleTranslator = "translator"
+	RoleEditor     = "editor"
+)
+
+// BookAuthor = baris join table book_authors, diurutkan dengan Position
+type BookAuthor struct {
+	BookID   uuid.UUID     `json:"-" gorm:"type:uuid;primaryKey"`
+	AuthorID uuid.UUID     `json:"author_id" gorm:"type:uuid;primaryKey"`
+	Role     string        `json:"role" gorm:"size:20;primaryKey"`
+	Position int           `json:"position" gorm:"not null"`
+	Author   author.Author `json:"author" gorm:"foreignKey:AuthorID;references:ID"`
+}
+
+func (BookAuthor) TableName() string {
+	return "book_authors"
+}
@@ -5,12 +5,14 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/qullDev/book_API/internal/domain/category"
+	"github.com/qullDev/book_API/internal/domain/user"
 	"gorm.io/gorm"
 )
 
 type Book struct {
 	ID          uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey"`
 	Title       string            `json:"title" gorm:"size:200;not null"`
+	ISBN        *string           `json:"isbn" gorm:"size:13;uniqueIndex"` // selalu disimpan sebagai ISBN-13
 	CategoryID  uuid.UUID         `json:"category_id" gorm:"type:uuid;not null"`
 	Category    category.Category `json:"category" gorm:"foreignKey:CategoryID;references:ID"`
 	Description string            `json:"description" gorm:"type:text"`
@@ -19,10 +21,14 @@ type Book struct {
 	Price       float64           `json:"price" gorm:"not null"`
 	TotalPage   int               `json:"total_page" gorm:"not null"`
 	Thickness   string            `json:"thickness" gorm:"size:10;not null"`
+	Authors     []BookAuthor      `json:"authors" gorm:"foreignKey:BookID"`
 	CreatedAt   time.Time         `json:"created_at"`
-	CreatedBy   uuid.UUID         `json:"created_by" gorm:"type:uuid"`
-	ModifiedAt  time.Time         `json:"modified_at"`
-	ModifiedBy  uuid.UUID         `json:"modified_by" gorm:"type:uuid"`
+	CreatedBy   uuid.UUID         `json:"created_by_id" gorm:"type:uuid"`
+	ModifiedAt  time.Time         `json:"modified_at" gorm:"autoUpdateTime"`
+	ModifiedBy  uuid.UUID         `json:"modified_by_id" gorm:"type:uuid"`
+	Creator     *user.Summary     `json:"created_by" gorm:"foreignKey:CreatedBy"`
+	Modifier    *user.Summary     `json:"modified_by" gorm:"foreignKey:ModifiedBy"`
+	DeletedAt   gorm.DeletedAt    `json:"deleted_at" gorm:"index" swaggertype:"string" format:"date-time"`
 }
 
 func (b *Book) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,34 @@
+package book
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"gorm.io/gorm"
+)
+
+// RevisionBaseline action revisi awal untuk buku yang sudah ada sebelum riwayat revisi dicatat
+const RevisionBaseline = "baseline"
+
+// Revision = snapshot lengkap buku (termasuk author) setiap kali isinya berubah
+type Revision struct {
+	ID        uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey"`
+	BookID    uuid.UUID     `json:"book_id" gorm:"type:uuid;not null;uniqueIndex:idx_book_revisions_number"`
+	Number    int           `json:"number" gorm:"not null;uniqueIndex:idx_book_revisions_number"`
+	Action    string        `json:"action" gorm:"size:20;not null"` // baseline atau action audit: create, update, rollback
+	Snapshot  audit.JSON    `json:"snapshot" swaggertype:"object"`
+	CreatedAt time.Time     `json:"created_at"`
+	CreatedBy uuid.UUID     `json:"created_by_id" gorm:"type:uuid"`
+	Creator   *user.Summary `json:"created_by" gorm:"foreignKey:CreatedBy"`
+}
+
+func (Revision) TableName() string {
+	return "book_revisions"
+}
+
+func (r *Revision) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
@@ -4,16 +4,20 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/user"
 	"gorm.io/gorm"
 )
 
 type Category struct {
-	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
-	Name       string    `json:"name" gorm:"uniqueIndex;size:100;not null"`
-	CreatedAt  time.Time `json:"created_at"`
-	CreatedBy  uuid.UUID `json:"created_by" gorm:"type:uuid"`
-	ModifiedAt time.Time `json:"modified_at"`
-	ModifiedBy uuid.UUID `json:"modified_by" gorm:"type:uuid"`
+	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	Name       string         `json:"name" gorm:"uniqueIndex:idx_categories_name_active,where:deleted_at IS NULL;size:100;not null"` // unik di antara kategori aktif saja
+	CreatedAt  time.Time      `json:"created_at"`
+	CreatedBy  uuid.UUID      `json:"created_by_id" gorm:"type:uuid"`
+	ModifiedAt time.Time      `json:"modified_at" gorm:"autoUpdateTime"`
+	ModifiedBy uuid.UUID      `json:"modified_by_id" gorm:"type:uuid"`
+	Creator    *user.Summary  `json:"created_by" gorm:"foreignKey:CreatedBy"`
+	Modifier   *user.Summary  `json:"modified_by" gorm:"foreignKey:ModifiedBy"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string" format:"date-time"`
 }
 
 func (c *Category) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,59 @@
+package oauth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyPKCE(t *testing.T) {
+	// vektor RFC 7636 lampiran B
+	const (
+		verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	)
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		want      bool
+	}{
+		{"rfc 7636 appendix b", verifier, challenge, true},
+		{"wrong verifier", strings.Replace(verifier, "d", "e", 1), challenge, false},
+		{"plain method not accepted", verifier, verifier, false},
+		{"empty challenge", verifier, "", false},
+		{"verifier too short", verifier[:42], challenge, false},
+		{"verifier too long", strings.Repeat("a", 129), challenge, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyPKCE(tt.verifier, tt.challenge); got != tt.want {
+				t.Errorf("VerifyPKCE = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientVerifySecret(t *testing.T) {
+	secret, hash, err := NewClientSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		client Client
+		secret string
+		want   bool
+	}{
+		{"correct", Client{SecretHash: hash}, secret, true},
+		{"wrong", Client{SecretHash: hash}, secret + "x", false},
+		{"public client", Client{SecretHash: hash, Public: true}, secret, false},
+		{"no secret stored", Client{}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.client.VerifySecret(tt.secret); got != tt.want {
+				t.Errorf("VerifySecret = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
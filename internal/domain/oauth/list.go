@@ -0,0 +1,42 @@
+package oauth
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// List daftar nilai tanpa spasi (redirect URI, grant type) yang disimpan sebagai teks dipisah spasi
+type List []string
+
+func (List) GormDataType() string {
+	return "text"
+}
+
+func (l List) Value() (driver.Value, error) {
+	return strings.Join(l, " "), nil
+}
+
+func (l *List) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+	case []byte:
+		*l = strings.Fields(string(v))
+	case string:
+		*l = strings.Fields(v)
+	default:
+		return fmt.Errorf("oauth.List: tipe tidak didukung %T", src)
+	}
+	return nil
+}
+
+// Has true jika nilai ada di daftar (cocok persis)
+func (l List) Has(v string) bool {
+	for _, x := range l {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
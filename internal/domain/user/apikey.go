@@ -0,0 +1,77 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix awalan semua API key, memudahkan secret scanner mengenali key yang bocor
+const APIKeyPrefix = "bk_"
+
+const apiKeyIDLen = 12 // panjang prefix hex yang disimpan apa adanya untuk lookup
+
+// APIKey kredensial jangka panjang milik user untuk akses mesin-ke-mesin.
+// Format key: bk_{prefix}_{secret}; hanya prefix dan hash secret yang disimpan.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	Prefix     string     `json:"prefix" gorm:"size:16;not null;uniqueIndex"`
+	SecretHash string     `json:"-" gorm:"size:64;not null"`
+	Scopes     Scopes     `json:"scopes" gorm:"not null"`
+	ExpiresAt  *time.Time `json:"expires_at"` // nil = tidak kedaluwarsa
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip" gorm:"size:45"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	k.ID = uuid.New()
+	return
+}
+
+// Expired true jika key punya masa berlaku yang sudah lewat
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// NewAPIKeySecret buat key baru; key lengkap hanya dikembalikan sekali ke pemilik
+func NewAPIKeySecret() (key, prefix, secretHash string, err error) {
+	id := make([]byte, apiKeyIDLen/2)
+	if _, err = rand.Read(id); err != nil {
+		return
+	}
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return
+	}
+	prefix = hex.EncodeToString(id)
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	return APIKeyPrefix + prefix + "_" + encoded, prefix, HashAPIKeySecret(encoded), nil
+}
+
+// ParseAPIKey pisahkan prefix dan secret; ok false jika format tidak sesuai
+func ParseAPIKey(key string) (prefix, secret string, ok bool) {
+	rest, found := strings.CutPrefix(key, APIKeyPrefix)
+	if !found || len(rest) < apiKeyIDLen+2 || rest[apiKeyIDLen] != '_' {
+		return "", "", false
+	}
+	return rest[:apiKeyIDLen], rest[apiKeyIDLen+1:], true
+}
+
+// HashAPIKeySecret sha256 secret; secret acak 256 bit sehingga hash cepat sudah cukup
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,34 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecoveryCode kode cadangan sekali pakai untuk login tanpa authenticator; hanya hash yang disimpan
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+func (r *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
+
+// RoleSetting kebijakan per role yang bisa diubah admin; role tanpa baris memakai nilai default
+type RoleSetting struct {
+	Role        string    `json:"role" gorm:"size:20;primaryKey"`
+	MFARequired bool      `json:"mfa_required" gorm:"column:mfa_required;not null;default:false"`
+	ModifiedAt  time.Time `json:"modified_at" gorm:"autoUpdateTime"`
+	ModifiedBy  uuid.UUID `json:"modified_by_id" gorm:"type:uuid"`
+}
@@ -8,16 +8,29 @@ import (
 )
 
 type User struct {
-	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
-	Username   string    `json:"username" gorm:"uniqueIndex;size:50;not null"`
-	Password   string    `json:"password" gorm:"not null"`
-	CreatedAt  time.Time `json:"created_at"`
-	CreatedBy  uuid.UUID `json:"created_by" gorm:"type:uuid"`
-	ModifiedAt time.Time `json:"modified_at"`
-	ModifiedBy uuid.UUID `json:"modified_by" gorm:"type:uuid"`
+	ID                    uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Username              string    `json:"username" gorm:"uniqueIndex;size:50;not null"`
+	Email                 *string   `json:"email" gorm:"size:254;uniqueIndex"` // untuk reset password
+	Password              string    `json:"-" gorm:"not null"`
+	Role                  string    `json:"role" gorm:"size:20;not null;default:reader"`
+	Disabled              bool      `json:"disabled" gorm:"not null;default:false"`
+	PasswordResetRequired bool      `json:"password_reset_required" gorm:"not null;default:false"` // wajib ganti password sebelum memakai endpoint lain
+	MFAEnabled            bool      `json:"mfa_enabled" gorm:"column:mfa_enabled;not null;default:false"`
+	MFASecret             string    `json:"-" gorm:"column:mfa_secret;size:255"`              // secret TOTP aktif (base32, dienkripsi KEK)
+	MFAPendingSecret      string    `json:"-" gorm:"column:mfa_pending_secret;size:255"`      // secret yang belum dikonfirmasi saat enrollment (dienkripsi KEK)
+	MFALastStep           int64     `json:"-" gorm:"column:mfa_last_step;not null;default:0"` // time step TOTP terakhir yang dipakai, mencegah replay
+	CreatedAt             time.Time `json:"created_at"`
+	CreatedBy             uuid.UUID `json:"created_by_id" gorm:"type:uuid"`
+	ModifiedAt            time.Time `json:"modified_at" gorm:"autoUpdateTime"`
+	ModifiedBy            uuid.UUID `json:"modified_by_id" gorm:"type:uuid"`
+	Creator               *Summary  `json:"created_by" gorm:"foreignKey:CreatedBy"`
+	Modifier              *Summary  `json:"modified_by" gorm:"foreignKey:ModifiedBy"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	u.ID = uuid.New()
+	if u.Role == "" {
+		u.Role = RoleReader
+	}
 	return
 }
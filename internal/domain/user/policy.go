@@ -0,0 +1,51 @@
+package user
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Batas username & password; 72 byte = batas input bcrypt
+const (
+	MinUsernameLen = 3
+	MaxUsernameLen = 50
+	MinPasswordLen = 8
+	MaxPasswordLen = 72
+)
+
+// ValidateUsername huruf, angka, titik, underscore dan strip; 3-50 karakter
+func ValidateUsername(username string) error {
+	if len(username) < MinUsernameLen || len(username) > MaxUsernameLen {
+		return errors.New("username harus 3 sampai 50 karakter")
+	}
+	for _, r := range username {
+		if !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-')) {
+			return errors.New("username hanya boleh berisi huruf, angka, titik, underscore dan strip")
+		}
+	}
+	return nil
+}
+
+// ValidatePassword minimal 8 karakter, mengandung huruf dan angka, dan tidak sama dengan username
+func ValidatePassword(username, password string) error {
+	if len(password) < MinPasswordLen || len(password) > MaxPasswordLen {
+		return errors.New("password harus 8 sampai 72 karakter")
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return errors.New("password harus mengandung huruf dan angka")
+	}
+	if username != "" && strings.EqualFold(password, username) {
+		return errors.New("password tidak boleh sama dengan username")
+	}
+	return nil
+}
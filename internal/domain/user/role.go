@@ -0,0 +1,62 @@
+package user
+
+// Role user
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleReader = "reader"
+)
+
+// Permission yang dicek middleware otorisasi per route
+const (
+	PermBooksRead       = "books:read"
+	PermBooksWrite      = "books:write"
+	PermCategoriesRead  = "categories:read"
+	PermCategoriesWrite = "categories:write"
+	PermAuthorsRead     = "authors:read"
+	PermAuthorsWrite    = "authors:write"
+	PermAuditRead       = "audit:read"
+	PermUsersManage     = "users:manage"
+)
+
+// rolePermissions = matriks permission tiap role
+var rolePermissions = map[string][]string{
+	RoleReader: {
+		PermBooksRead, PermCategoriesRead, PermAuthorsRead,
+	},
+	RoleEditor: {
+		PermBooksRead, PermCategoriesRead, PermAuthorsRead,
+		PermBooksWrite, PermCategoriesWrite, PermAuthorsWrite,
+	},
+	RoleAdmin: {
+		PermBooksRead, PermCategoriesRead, PermAuthorsRead,
+		PermBooksWrite, PermCategoriesWrite, PermAuthorsWrite,
+		PermAuditRead, PermUsersManage,
+	},
+}
+
+// Roles daftar role yang valid, urut dari hak akses terbesar
+func Roles() []string {
+	return []string{RoleAdmin, RoleEditor, RoleReader}
+}
+
+// ValidRole true jika role dikenal
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Permissions daftar permission milik role; role tidak dikenal tidak punya permission
+func Permissions(role string) []string {
+	return append([]string(nil), rolePermissions[role]...)
+}
+
+// HasPermission cek apakah role memiliki permission perm
+func HasPermission(role, perm string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package user
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// ScopeAdmin scope gabungan untuk permission administrasi (audit log dan pengelolaan user)
+const ScopeAdmin = "admin"
+
+// adminScopePermissions permission yang hanya bisa diberikan lewat scope admin
+var adminScopePermissions = []string{PermAuditRead, PermUsersManage}
+
+// Scopes daftar scope yang disimpan sebagai teks dipisah spasi, seperti parameter scope OAuth.
+// Scope bernama sama dengan permission (mis. books:read), kecuali permission administrasi yang memakai ScopeAdmin.
+type Scopes []string
+
+// ParseScopes pecah string scope OAuth (dipisah spasi); scope duplikat dibuang
+func ParseScopes(raw string) Scopes {
+	out := Scopes{}
+	for _, sc := range strings.Fields(raw) {
+		if !out.Has(sc) {
+			out = append(out, sc)
+		}
+	}
+	return out
+}
+
+// RoleScopes semua scope yang boleh diminta oleh role
+func RoleScopes(role string) Scopes {
+	out := Scopes{}
+	for _, p := range rolePermissions[role] {
+		if !isAdminPermission(p) {
+			out = append(out, p)
+		}
+	}
+	if HasPermission(role, PermUsersManage) {
+		out = append(out, ScopeAdmin)
+	}
+	return out
+}
+
+// AllScopes semua scope yang dikenal, gabungan scope semua role
+func AllScopes() Scopes {
+	out := Scopes{}
+	for _, r := range Roles() {
+		for _, sc := range RoleScopes(r) {
+			if !out.Has(sc) {
+				out = append(out, sc)
+			}
+		}
+	}
+	return out
+}
+
+func isAdminPermission(perm string) bool {
+	for _, p := range adminScopePermissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+func (Scopes) GormDataType() string {
+	return "text"
+}
+
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+func (s *Scopes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*s = nil
+	case []byte:
+		*s = strings.Fields(string(v))
+	case string:
+		*s = strings.Fields(v)
+	default:
+		return fmt.Errorf("user.Scopes: tipe tidak didukung %T", src)
+	}
+	return nil
+}
+
+func (s Scopes) String() string {
+	return strings.Join(s, " ")
+}
+
+// Has true jika scope ada di daftar
+func (s Scopes) Has(scope string) bool {
+	for _, v := range s {
+		if v == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Grants true jika scope mengizinkan permission perm
+func (s Scopes) Grants(perm string) bool {
+	return s.Has(perm) || (isAdminPermission(perm) && s.Has(ScopeAdmin))
+}
+
+// Contains true jika semua scope other ada di s
+func (s Scopes) Contains(other Scopes) bool {
+	for _, sc := range other {
+		if !s.Has(sc) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersect scope yang ada di s dan other, urutan mengikuti s
+func (s Scopes) Intersect(other Scopes) Scopes {
+	out := Scopes{}
+	for _, sc := range s {
+		if other.Has(sc) {
+			out = append(out, sc)
+		}
+	}
+	return out
+}
+
+// ValidateScopes pastikan setiap scope boleh diminta oleh role; scope duplikat dibuang
+func ValidateScopes(role string, scopes []string) (Scopes, error) {
+	allowed := RoleScopes(role)
+	out := Scopes{}
+	for _, sc := range scopes {
+		if !allowed.Has(sc) {
+			return nil, fmt.Errorf("scope tidak dikenal atau di luar hak akses role %s: %s", role, sc)
+		}
+		if !out.Has(sc) {
+			out = append(out, sc)
+		}
+	}
+	return out, nil
+}
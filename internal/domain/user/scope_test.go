@@ -0,0 +1,99 @@
+package user
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScopesContains(t *testing.T) {
+	s := Scopes{PermBooksRead, PermBooksWrite, ScopeAdmin}
+	tests := []struct {
+		name  string
+		other Scopes
+		want  bool
+	}{
+		{"empty", Scopes{}, true},
+		{"subset", Scopes{PermBooksRead}, true},
+		{"equal", Scopes{ScopeAdmin, PermBooksWrite, PermBooksRead}, true},
+		{"missing one", Scopes{PermBooksRead, PermAuthorsRead}, false},
+		{"admin permission is not a scope", Scopes{PermUsersManage}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Contains(tt.other); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.other, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopesIntersect(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Scopes
+		o    Scopes
+		want Scopes
+	}{
+		{"order follows receiver", Scopes{PermBooksWrite, PermBooksRead}, Scopes{PermBooksRead, PermBooksWrite}, Scopes{PermBooksWrite, PermBooksRead}},
+		{"partial", Scopes{PermBooksRead, ScopeAdmin}, Scopes{PermBooksRead}, Scopes{PermBooksRead}},
+		{"disjoint", Scopes{PermBooksRead}, Scopes{PermAuthorsRead}, Scopes{}},
+		{"nil other", Scopes{PermBooksRead}, nil, Scopes{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Intersect(tt.o); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Intersect = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopesGrants(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Scopes
+		perm string
+		want bool
+	}{
+		{"direct scope", Scopes{PermBooksRead}, PermBooksRead, true},
+		{"missing scope", Scopes{PermBooksRead}, PermBooksWrite, false},
+		{"admin grants audit", Scopes{ScopeAdmin}, PermAuditRead, true},
+		{"admin grants user management", Scopes{ScopeAdmin}, PermUsersManage, true},
+		{"admin does not grant writes", Scopes{ScopeAdmin}, PermBooksWrite, false},
+		{"empty", Scopes{}, PermBooksRead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Grants(tt.perm); got != tt.want {
+				t.Errorf("Grants(%s) = %v, want %v", tt.perm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		scopes  []string
+		want    Scopes
+		wantErr bool
+	}{
+		{"reader read scopes", RoleReader, []string{PermBooksRead, PermBooksRead}, Scopes{PermBooksRead}, false},
+		{"reader cannot write", RoleReader, []string{PermBooksWrite}, nil, true},
+		{"editor cannot admin", RoleEditor, []string{ScopeAdmin}, nil, true},
+		{"admin scope", RoleAdmin, []string{ScopeAdmin}, Scopes{ScopeAdmin}, false},
+		{"raw admin permission rejected", RoleAdmin, []string{PermUsersManage}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateScopes(tt.role, tt.scopes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateScopes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
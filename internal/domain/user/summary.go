@@ -0,0 +1,13 @@
+package user
+
+import "github.com/google/uuid"
+
+// Summary = data user minimal yang di-embed di response (created_by / modified_by)
+type Summary struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+func (Summary) TableName() string {
+	return "users"
+}
@@ -0,0 +1,708 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"github.com/qullDev/book_API/internal/pkg/actor"
+	appauth "github.com/qullDev/book_API/internal/pkg/auth"
+	"github.com/qullDev/book_API/internal/pkg/password"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errLastAdmin dikembalikan jika perubahan akan menghapus admin aktif terakhir
+var errLastAdmin = errors.New("minimal harus ada satu admin aktif")
+
+// AdminUserHandler endpoint administrasi user; route-nya dibatasi permission users:manage di router
+type AdminUserHandler struct {
+	db        *gorm.DB
+	ts        *appauth.TokenStore
+	passwords *password.Manager
+	guard     *appauth.LoginGuard
+}
+
+func NewAdminUserHandler(db *gorm.DB, ts *appauth.TokenStore, passwords *password.Manager, guard *appauth.LoginGuard) *AdminUserHandler {
+	return &AdminUserHandler{db: db, ts: ts, passwords: passwords, guard: guard}
+}
+
+// Register daftarkan route pada group /api/admin
+func (h *AdminUserHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/roles", h.Roles)
+	rg.PUT("/roles/:role/mfa", h.UpdateRoleMFA)
+	rg.GET("/users", h.List)
+	rg.POST("/users", h.Create)
+	rg.GET("/users/:id", h.Detail)
+	rg.DELETE("/users/:id", h.Delete)
+	rg.PUT("/users/:id/role", h.UpdateRole)
+	rg.POST("/users/:id/disable", h.Disable)
+	rg.POST("/users/:id/enable", h.Enable)
+	rg.POST("/users/:id/reset-password", h.ResetPassword)
+	rg.POST("/users/:id/revoke-sessions", h.RevokeSessions)
+	rg.GET("/users/:id/api-keys", h.APIKeys)
+	rg.DELETE("/users/:id/api-keys", h.DeleteAPIKeys)
+	rg.POST("/users/:id/unlock", h.Unlock)
+	rg.POST("/users/:id/mfa/reset", h.ResetMFA)
+}
+
+type roleResp struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	MFARequired bool     `json:"mfa_required"`
+}
+
+type roleMFAReq struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+type updateRoleReq struct {
+	Role string `json:"role" binding:"required,oneof=admin editor reader"`
+}
+
+type adminCreateUserReq struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Role     string `json:"role" binding:"omitempty,oneof=admin editor reader"`
+	// PasswordResetRequired paksa user mengganti password saat login pertama
+	PasswordResetRequired bool `json:"password_reset_required"`
+}
+
+type resetPasswordResp struct {
+	Message           string `json:"message"`
+	TemporaryPassword string `json:"temporary_password"`
+}
+
+var adminUserSortFields = map[string]sortField{
+	"username":   {Column: "username", Type: "text"},
+	"created_at": {Column: "created_at", Type: "timestamptz"},
+}
+
+// @Summary List roles
+// @Description Get every role with its permissions and MFA policy
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string][]roleResp
+// @Failure 403 {object} gin.H
+// @Router /api/admin/roles [get]
+func (h *AdminUserHandler) Roles(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var settings []user.RoleSetting
+	if err := db.Find(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data role"})
+		return
+	}
+	mfa := map[string]bool{}
+	for _, s := range settings {
+		mfa[s.Role] = s.MFARequired
+	}
+	items := []roleResp{}
+	for _, r := range user.Roles() {
+		items = append(items, roleResp{Role: r, Permissions: user.Permissions(r), MFARequired: mfa[r]})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// @Summary Set role MFA policy
+// @Description Require (or stop requiring) TOTP two-factor authentication for every user of a role. Users without MFA get tokens that only allow enrolling MFA via /api/users/me/mfa until they finish.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param role path string true "Role" Enums(admin, editor, reader)
+// @Param policy body roleMFAReq true "MFA policy" example({"required": true})
+// @Success 200 {object} map[string]roleResp
+// @Failure 400,403,404 {object} gin.H
+// @Router /api/admin/roles/{role}/mfa [put]
+func (h *AdminUserHandler) UpdateRoleMFA(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	role := c.Param("role")
+	if !user.ValidRole(role) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "role tidak ditemukan"})
+		return
+	}
+	var req roleMFAReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		old, err := mfaRequired(tx, role)
+		if err != nil {
+			return err
+		}
+		by, _ := actor.UserID(c.Request.Context())
+		setting := user.RoleSetting{Role: role, MFARequired: *req.Required, ModifiedBy: by}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "role"}},
+			DoUpdates: clause.AssignmentColumns([]string{"mfa_required", "modified_at", "modified_by"}),
+		}).Create(&setting).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionUpdate, EntityType: entityRole, EntityID: role, Before: gin.H{"mfa_required": old}, After: gin.H{"mfa_required": *req.Required}})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengubah kebijakan MFA role"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": roleResp{Role: role, Permissions: user.Permissions(role), MFARequired: *req.Required}})
+}
+
+// @Summary List users
+// @Description Get paginated list of users
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Filter by username or email (case-insensitive, partial)"
+// @Param role query string false "Filter by role" Enums(admin, editor, reader)
+// @Param disabled query bool false "Filter by disabled state"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Param sort query string false "Sort field, prefix with - for descending" Enums(username, -username, created_at, -created_at)
+// @Success 200 {object} pageResp
+// @Failure 400,403 {object} gin.H
+// @Router /api/admin/users [get]
+func (h *AdminUserHandler) List(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	order, err := parseSort(c.Query("sort"), adminUserSortFields, "username")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	q := db.Model(&user.User{})
+	if v := c.Query("q"); v != "" {
+		q = q.Where("username ILIKE ? OR email ILIKE ?", "%"+v+"%", "%"+v+"%")
+	}
+	if v := c.Query("role"); v != "" {
+		if !user.ValidRole(v) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "role tidak valid"})
+			return
+		}
+		q = q.Where("role = ?", v)
+	}
+	if v := c.Query("disabled"); v != "" {
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "disabled harus true atau false"})
+			return
+		}
+		q = q.Where("disabled = ?", disabled)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	items := []user.User{}
+	if err := q.Scopes(withUsers).Order(order).Order("id asc").Limit(p.PageSize).Offset(p.Offset).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	c.JSON(http.StatusOK, newPageResp(c, p, total, items))
+}
+
+// @Summary Create user
+// @Description Create a user with any role
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user body adminCreateUserReq true "User data" example({"username": "editor01", "password": "rahasia123", "email": "editor01@example.com", "role": "editor", "password_reset_required": true})
+// @Success 201 {object} map[string]user.User
+// @Failure 400,403,409 {object} gin.H
+// @Router /api/admin/users [post]
+func (h *AdminUserHandler) Create(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req adminCreateUserReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	if err := user.ValidateUsername(req.Username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := user.ValidatePassword(req.Username, req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if taken, err := usernameTaken(db, req.Username, uuid.Nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menambahkan user"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"message": "username sudah dipakai"})
+		return
+	}
+	email := normalizeEmail(req.Email)
+	if taken, err := emailTaken(db, email, uuid.Nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menambahkan user"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"message": "email sudah dipakai"})
+		return
+	}
+	hashed, err := h.passwords.Hash(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menambahkan user"})
+		return
+	}
+
+	item := user.User{Username: req.Username, Email: email, Password: hashed, Role: req.Role, PasswordResetRequired: req.PasswordResetRequired}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionCreate, EntityType: entityUser, EntityID: item.ID.String(), After: item})
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"message": duplicateUserMessage(db, item.Username, item.Email, uuid.Nil)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menambahkan user"})
+		return
+	}
+	if err := db.Scopes(withUsers).First(&item, "id = ?", item.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+// @Summary Get user detail
+// @Description Get detail of a user
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} map[string]user.User
+// @Failure 400,403,404 {object} gin.H
+// @Router /api/admin/users/{id} [get]
+func (h *AdminUserHandler) Detail(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	item, ok := h.load(c, db.Scopes(withUsers))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// @Summary Change user role
+// @Description Assign a role to a user. The new role applies to access tokens issued after the change (next login or refresh).
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Param role body updateRoleReq true "New role" example({"role": "editor"})
+// @Success 200 {object} map[string]user.User
+// @Failure 400,403,404,409 {object} gin.H
+// @Router /api/admin/users/{id}/role [put]
+func (h *AdminUserHandler) UpdateRole(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req updateRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	item, ok := h.load(c, db)
+	if !ok {
+		return
+	}
+	oldRole := item.Role
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if req.Role != user.RoleAdmin {
+			if err := ensureOtherAdmin(tx, item); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&user.User{}).Where("id = ?", item.ID).Update("role", req.Role).Error; err != nil {
+			return err
+		}
+		// snapshot hanya field yang berubah
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionUpdate, EntityType: entityUser, EntityID: item.ID.String(), Before: gin.H{"role": oldRole}, After: gin.H{"role": req.Role}})
+	})
+	if err != nil {
+		h.fail(c, err, "gagal mengubah role user")
+		return
+	}
+	h.respond(c, db, item.ID)
+}
+
+// @Summary Disable user
+// @Description Disable a user account and revoke all of its refresh and access tokens. Disabled users cannot log in or refresh tokens.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} map[string]user.User
+// @Failure 400,403,404,409 {object} gin.H
+// @Router /api/admin/users/{id}/disable [post]
+func (h *AdminUserHandler) Disable(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+// @Summary Enable user
+// @Description Re-enable a disabled user account
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} map[string]user.User
+// @Failure 400,403,404 {object} gin.H
+// @Router /api/admin/users/{id}/enable [post]
+func (h *AdminUserHandler) Enable(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+func (h *AdminUserHandler) setDisabled(c *gin.Context, disabled bool) {
+	db := h.db.WithContext(c.Request.Context())
+	item, ok := h.load(c, db)
+	if !ok {
+		return
+	}
+	if disabled && isSelf(c, item.ID) {
+		c.JSON(http.StatusConflict, gin.H{"message": "tidak bisa menonaktifkan akun sendiri"})
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if disabled {
+			if err := ensureOtherAdmin(tx, item); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&user.User{}).Where("id = ?", item.ID).Update("disabled", disabled).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionUpdate, EntityType: entityUser, EntityID: item.ID.String(), Before: gin.H{"disabled": item.Disabled}, After: gin.H{"disabled": disabled}})
+	})
+	if err != nil {
+		h.fail(c, err, "gagal mengubah status user")
+		return
+	}
+	if disabled {
+		if err := h.ts.RevokeAllTokens(c.Request.Context(), item.ID.String()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "user dinonaktifkan, tetapi gagal mencabut sesi"})
+			return
+		}
+	}
+	h.respond(c, db, item.ID)
+}
+
+// @Summary Delete user
+// @Description Permanently delete a user and revoke all of its refresh and access tokens
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} gin.H
+// @Failure 400,403,404,409 {object} gin.H
+// @Router /api/admin/users/{id} [delete]
+func (h *AdminUserHandler) Delete(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	item, ok := h.load(c, db)
+	if !ok {
+		return
+	}
+	if isSelf(c, item.ID) {
+		c.JSON(http.StatusConflict, gin.H{"message": "tidak bisa menghapus akun sendiri"})
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureOtherAdmin(tx, item); err != nil {
+			return err
+		}
+		if err := tx.Delete(&user.User{}, "id = ?", item.ID).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionDelete, EntityType: entityUser, EntityID: item.ID.String(), Before: item})
+	})
+	if err != nil {
+		h.fail(c, err, "gagal menghapus user")
+		return
+	}
+	if err := h.ts.RevokeAllTokens(c.Request.Context(), item.ID.String()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "user dihapus, tetapi gagal mencabut sesi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "user berhasil dihapus"})
+}
+
+// @Summary Force password reset
+// @Description Replace the password with a temporary one, require the user to change it on next login, revoke all refresh and access tokens, delete all API keys and disable the client_credentials OAuth clients the user owns. The temporary password is only returned once.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} resetPasswordResp
+// @Failure 400,403,404 {object} gin.H
+// @Router /api/admin/users/{id}/reset-password [post]
+func (h *AdminUserHandler) ResetPassword(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	item, ok := h.load(c, db)
+	if !ok {
+		return
+	}
+	temp, err := temporaryPassword()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mereset password"})
+		return
+	}
+	hashed, err := h.passwords.Hash(temp)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mereset password"})
+		return
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user.User{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+			"password":                hashed,
+			"password_reset_required": true,
+		}).Error; err != nil {
+			return err
+		}
+		if err := revokeAPIKeys(tx, c, item.ID, uuid.Nil); err != nil {
+			return err
+		}
+		if err := disableOwnedClients(tx, c, item.ID, uuid.Nil); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionPassword, EntityType: entityUser, EntityID: item.ID.String(), After: gin.H{"forced_reset": true}})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mereset password"})
+		return
+	}
+	if err := h.ts.RevokeAllTokens(c.Request.Context(), item.ID.String()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "password direset, tetapi gagal mencabut sesi"})
+		return
+	}
+	c.JSON(http.StatusOK, resetPasswordResp{Message: "password berhasil direset", TemporaryPassword: temp})
+}
+
+// @Summary Revoke user sessions
+// @Description Revoke all refresh and access tokens of a user, delete their API keys and disable the client_credentials OAuth clients they own
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} gin.H
+// @Failure 400,403,404 {object} gin.H
+// @Router /api/admin/users/{id}/revoke-sessions [post]
+func (h *AdminUserHandler) RevokeSessions(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	item, ok := h.load(c, db)
+	if !ok {
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := revokeAPIKeys(tx, c, item.ID, uuid.Nil); err != nil {
+			return err
+		}
+		if err := disableOwnedClients(tx, c, item.ID, uuid.Nil); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionLogout, EntityType: entityUser, EntityID: item.ID.String(), After: gin.H{"scope": "all", "by_admin": true}})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencabut sesi user"})
+		return
+	}
+	if err := h.ts.RevokeAllTokens(c.Request.Context(), item.ID.String()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencabut sesi user"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "semua sesi user berhasil dicabut"})
+}
+
+// @Summary List user API keys
+// @Description Get the API keys of a user, newest first. The secret part of a key is never returned.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} map[string][]user.APIKey
+// @Failure 400,403,404 {object} gin.H
+// @Router /api/admin/users/{id}/api-keys [get]
+func (h *AdminUserHandler) APIKeys(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	item, ok := h.load(c, db)
+	if !ok {
+		return
+	}
+	items := []user.APIKey{}
+	if err := db.Where("user_id = ?", item.ID).Order("created_at desc").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil daftar API key"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// @Summary Delete user API keys
+// @Description Delete all API keys of a user; requests using them are rejected immediately
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} gin.H
+// @Failure 400,403,404 {object} gin.H
+// @Router /api/admin/users/{id}/api-keys [delete]
+func (h *AdminUserHandler) DeleteAPIKeys(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	item, ok := h.load(c, db)
+	if !ok {
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return revokeAPIKeys(tx, c, item.ID, uuid.Nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus API key"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "semua API key user berhasil dihapus"})
+}
+
+// @Summary Unlock user login
+// @Description Clear failed login counters and the temporary lockout of a user. Locks on client IPs are not affected.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} gin.H
+// @Failure 400,403,404 {object} gin.H
+// @Router /api/admin/users/{id}/unlock [post]
+func (h *AdminUserHandler) Unlock(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	item, ok := h.load(c, db)
+	if !ok {
+		return
+	}
+	if err := h.guard.Unlock(c.Request.Context(), item.Username); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuka kunci user"})
+		return
+	}
+	if err := recordAudit(db, c, auditEntry{Action: audit.ActionUnlock, EntityType: entityUser, EntityID: item.ID.String(), After: gin.H{"username": item.Username}}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencatat audit"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "kunci login user berhasil dibuka"})
+}
+
+// @Summary Reset user MFA
+// @Description Turn off TOTP two-factor authentication of a user who lost their authenticator, delete their recovery codes and revoke all of their refresh and access tokens. If the user's role requires MFA they must enroll again after the next login.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} map[string]user.User
+// @Failure 400,403,404 {object} gin.H
+// @Router /api/admin/users/{id}/mfa/reset [post]
+func (h *AdminUserHandler) ResetMFA(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	item, ok := h.load(c, db)
+	if !ok {
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := clearMFA(tx, item.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionMFADisable, EntityType: entityUser, EntityID: item.ID.String(), Before: gin.H{"mfa_enabled": item.MFAEnabled}, After: gin.H{"mfa_enabled": false, "by_admin": true}})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mereset MFA user"})
+		return
+	}
+	if err := h.ts.RevokeAllTokens(c.Request.Context(), item.ID.String()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencabut sesi user"})
+		return
+	}
+	h.respond(c, db, item.ID)
+}
+
+// load ambil user dari param :id; response error sudah ditulis jika ok false
+func (h *AdminUserHandler) load(c *gin.Context, db *gorm.DB) (user.User, bool) {
+	var item user.User
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return item, false
+	}
+	if err := db.First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "user tidak ditemukan"})
+			return item, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return item, false
+	}
+	return item, true
+}
+
+// respond kirim data user terbaru beserta ringkasan created_by/modified_by
+func (h *AdminUserHandler) respond(c *gin.Context, db *gorm.DB, id uuid.UUID) {
+	var item user.User
+	if err := db.Scopes(withUsers).First(&item, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+func (h *AdminUserHandler) fail(c *gin.Context, err error, msg string) {
+	if err == errLastAdmin {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"message": msg})
+}
+
+// ensureOtherAdmin pastikan masih ada admin aktif selain u jika u sendiri admin aktif.
+// Baris admin dikunci agar dua perubahan bersamaan tidak menghabiskan semua admin.
+func ensureOtherAdmin(tx *gorm.DB, u user.User) error {
+	if u.Role != user.RoleAdmin || u.Disabled {
+		return nil
+	}
+	var admins []uuid.UUID
+	if err := tx.Model(&user.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND disabled = ?", user.RoleAdmin, false).Pluck("id", &admins).Error; err != nil {
+		return err
+	}
+	for _, id := range admins {
+		if id != u.ID {
+			return nil
+		}
+	}
+	return errLastAdmin
+}
+
+func isSelf(c *gin.Context, id uuid.UUID) bool {
+	self, _ := actor.UserID(c.Request.Context())
+	return self == id
+}
+
+// temporaryPassword 16 karakter acak (huruf kecil dan angka)
+func temporaryPassword() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToLower(base32.StdEncoding.EncodeToString(b)), nil
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/pkg/actor"
+	"gorm.io/gorm"
+)
+
+// Jenis entity pada audit log
+const (
+	entityBook     = "book"
+	entityCategory = "category"
+	entityAuthor   = "author"
+	entityUser     = "user"
+	entityRole     = "role"
+	entityAPIKey   = "api_key"
+	entityClient   = "oauth_client"
+)
+
+type AuditHandler struct {
+	db *gorm.DB
+}
+
+func NewAuditHandler(db *gorm.DB) *AuditHandler {
+	return &AuditHandler{db: db}
+}
+
+func (h *AuditHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+}
+
+// auditEntry data satu event; Before/After berisi model (akan di-snapshot ke JSON)
+type auditEntry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	ActorID    uuid.UUID // opsional, default user dari context request
+	Before     interface{}
+	After      interface{}
+}
+
+// recordAudit tulis audit event memakai tx yang sama dengan perubahan datanya
+func recordAudit(tx *gorm.DB, c *gin.Context, e auditEntry) error {
+	before, err := audit.Snapshot(e.Before)
+	if err != nil {
+		return err
+	}
+	after, err := audit.Snapshot(e.After)
+	if err != nil {
+		return err
+	}
+	ev := audit.Event{
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     before,
+		After:      after,
+		RequestID:  c.GetString("requestID"),
+		ClientIP:   c.ClientIP(),
+	}
+	if before != nil && after != nil {
+		changes, err := audit.Diff(before, after)
+		if err != nil {
+			return err
+		}
+		if ev.Changes, err = audit.Snapshot(changes); err != nil {
+			return err
+		}
+	}
+
+	actorID := e.ActorID
+	if actorID == uuid.Nil {
+		actorID, _ = actor.UserID(c.Request.Context())
+	}
+	if actorID != uuid.Nil {
+		ev.ActorID = &actorID
+	}
+	return tx.Create(&ev).Error
+}
+
+// @Summary List audit events
+// @Description Get paginated audit trail of mutations and auth events, newest first
+// @Tags audit
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param entity_type query string false "Entity type" Enums(book, category, author, user, role, api_key, oauth_client)
+// @Param entity_id query string false "Entity ID"
+// @Param actor_id query string false "User who performed the action" format(uuid)
+// @Param action query string false "Action, e.g. create, update, delete, login"
+// @Param from query string false "Only events at or after this time (RFC3339)"
+// @Param to query string false "Only events before this time (RFC3339)"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} pageResp
+// @Failure 400 {object} gin.H
+// @Router /api/audit [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	q := db.Model(&audit.Event{})
+	if v := c.Query("entity_type"); v != "" {
+		q = q.Where("entity_type = ?", v)
+	}
+	if v := c.Query("entity_id"); v != "" {
+		q = q.Where("entity_id = ?", v)
+	}
+	if v := c.Query("action"); v != "" {
+		q = q.Where("action = ?", v)
+	}
+	if v := c.Query("actor_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "actor_id tidak valid"})
+			return
+		}
+		q = q.Where("actor_id = ?", id)
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "from harus format RFC3339"})
+			return
+		}
+		q = q.Where("created_at >= ?", t)
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "to harus format RFC3339"})
+			return
+		}
+		q = q.Where("created_at < ?", t)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil audit log"})
+		return
+	}
+	items := []audit.Event{}
+	if err := q.Preload("Actor").Order("created_at desc").Order("id asc").Limit(p.PageSize).Offset(p.Offset).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, newPageResp(c, p, total, items))
+}
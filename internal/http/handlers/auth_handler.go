@@ -1,54 +1,85 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/qullDev/book_API/internal/config"
+	"github.com/qullDev/book_API/internal/domain/audit"
 	"github.com/qullDev/book_API/internal/domain/user"
 	appauth "github.com/qullDev/book_API/internal/pkg/auth"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/qullDev/book_API/internal/pkg/password"
+	"github.com/qullDev/book_API/internal/pkg/secret"
 	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
-	db  *gorm.DB
-	ts  *appauth.TokenStore
-	cfg *config.Config
+	db        *gorm.DB
+	ts        *appauth.TokenStore
+	cfg       *config.Config
+	passwords *password.Manager
+	guard     *appauth.LoginGuard
+	keys      *appauth.KeyRing
+	box       *secret.Box // dekripsi secret MFA
 }
 
-func NewAuthHandler(db *gorm.DB, ts *appauth.TokenStore, cfg *config.Config) *AuthHandler {
-	return &AuthHandler{db: db, ts: ts, cfg: cfg}
+func NewAuthHandler(db *gorm.DB, ts *appauth.TokenStore, cfg *config.Config, passwords *password.Manager, guard *appauth.LoginGuard, keys *appauth.KeyRing, box *secret.Box) *AuthHandler {
+	return &AuthHandler{db: db, ts: ts, cfg: cfg, passwords: passwords, guard: guard, keys: keys, box: box}
 }
 
 type loginReq struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Scope    string `json:"scope"` // opsional, dipisah spasi; default semua scope role
 }
 
 type refreshReq struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
+	Scope        string `json:"scope"` // opsional, harus bagian dari scope saat login
 }
 
 type tokenPairResp struct {
-	AccessToken      string `json:"access_token"`
-	RefreshToken     string `json:"refresh_token"`
-	TokenType        string `json:"token_type"`
-	ExpiresIn        int64  `json:"expires_in"`
-	RefreshExpiresIn int64  `json:"refresh_expires_in"`
-	UserID           string `json:"user_id,omitempty"`
-	Username         string `json:"username,omitempty"`
+	AccessToken           string `json:"access_token"`
+	RefreshToken          string `json:"refresh_token"`
+	TokenType             string `json:"token_type"`
+	ExpiresIn             int64  `json:"expires_in"`
+	RefreshExpiresIn      int64  `json:"refresh_expires_in"`
+	UserID                string `json:"user_id,omitempty"`
+	Username              string `json:"username,omitempty"`
+	Role                  string `json:"role,omitempty"`
+	Scope                 string `json:"scope"`                             // scope access token, dipisah spasi
+	PasswordResetRequired bool   `json:"password_reset_required,omitempty"` // true: hanya /api/users/me* dan logout yang bisa dipakai
+	MFAEnrollRequired     bool   `json:"mfa_enroll_required,omitempty"`     // true: role mewajibkan MFA, aktifkan lewat /api/users/me/mfa
+}
+
+// mfaChallengeResp response login jika user memakai MFA
+type mfaChallengeResp struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type mfaLoginReq struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // kode TOTP atau recovery code
 }
 
 // @Summary Login user
-// @Description Login with username and password
+// @Description Login with username and password. An optional space-separated scope limits the access token to a subset of the role's scopes (default: all of them). Users with MFA enabled receive an mfa_token challenge instead of the token pair; complete it with /api/users/login/mfa. Repeated failures per username or client IP are throttled with exponential backoff and a temporary lockout (429 with Retry-After).
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param loginRequest body loginReq true "Login credentials" example({"username": "admin", "password": "password123"})
-// @Success 200 {object} tokenPairResp "example={'access_token':'eyJhbG...','refresh_token':'eyJhbG...','token_type':'Bearer','expires_in':900,'refresh_expires_in':604800,'user_id':'550e8400-e29b-41d4-a716-446655440000','username':'admin'}"
-// @Failure 400,401 {object} gin.H "example={'message':'username atau password salah'}"
+// @Param loginRequest body loginReq true "Login credentials" example({"username": "admin", "password": "password123", "scope": "books:read categories:read"})
+// @Success 200 {object} tokenPairResp "Token pair, or mfaChallengeResp when MFA is enabled. example={'access_token':'eyJhbG...','refresh_token':'eyJhbG...','token_type':'Bearer','expires_in':900,'refresh_expires_in':604800,'user_id':'550e8400-e29b-41d4-a716-446655440000','username':'admin'}"
+// @Failure 400,401,403 {object} gin.H "example={'message':'username atau password salah'}"
+// @Failure 429 {object} gin.H "example={'message':'terlalu banyak percobaan login, coba lagi nanti','retry_after':30}"
 // @Router /api/users/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req loginReq
@@ -57,61 +88,235 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	ctx := c.Request.Context()
+	// percobaan dipesan sebelum password dicek agar request paralel tidak melewati batas
+	attempt, wait, err := h.guard.Reserve(ctx, req.Username, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses login"})
+		return
+	}
+	if wait > 0 {
+		tooManyAttempts(c, wait)
+		return
+	}
+	defer releaseAttempt(ctx, attempt)
+
 	var u user.User
 	if err := h.db.Where("username = ?", req.Username).First(&u).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "username atau password salah"})
+			// tetap hitung hash agar waktu respons tidak membocorkan username yang terdaftar
+			h.passwords.Verify("", req.Password)
+			h.audit(c, auditEntry{Action: audit.ActionLoginFailed, EntityType: entityUser, After: gin.H{"username": req.Username, "reason": "user_not_found"}})
+			h.loginFailed(c, attempt, req.Username, nil)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses login"})
+		return
+	}
+
+	// Verifikasi password; nilai yang bukan hash (plaintext lama) selalu ditolak
+	ok, rehash, err := h.passwords.Verify(u.Password, req.Password)
+	if !ok {
+		reason := "invalid_password"
+		if err == password.ErrUnknownFormat {
+			reason = "unsupported_hash"
+		} else if err != nil {
+			log.Println("verifikasi password gagal:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses login"})
+			return
+		}
+		h.audit(c, auditEntry{Action: audit.ActionLoginFailed, EntityType: entityUser, EntityID: u.ID.String(), After: gin.H{"username": u.Username, "reason": reason}})
+		h.loginFailed(c, attempt, req.Username, &u)
+		return
+	}
+	if rehash {
+		h.upgradeHash(c, u, req.Password)
+	}
+
+	if u.Disabled {
+		h.audit(c, auditEntry{Action: audit.ActionLoginFailed, EntityType: entityUser, EntityID: u.ID.String(), After: gin.H{"username": u.Username, "reason": "disabled"}})
+		c.JSON(http.StatusForbidden, gin.H{"message": "akun dinonaktifkan"})
+		return
+	}
+
+	// scope dicek setelah password benar agar tidak membocorkan role user
+	var scope user.Scopes
+	if req.Scope != "" {
+		if scope, err = user.ValidateScopes(u.Role, user.ParseScopes(req.Scope)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+	}
+
+	// MFA aktif: token pair baru diberikan setelah kode diverifikasi di /api/users/login/mfa.
+	// Penghitung gagal belum direset agar tebakan kode tetap dibatasi; percobaan ini dikembalikan lewat defer.
+	if u.MFAEnabled {
+		mfaToken, err := appauth.GenerateMFAToken(h.cfg, h.keys, u.ID, scope.String())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat token MFA"})
+			return
+		}
+		c.JSON(http.StatusOK, mfaChallengeResp{MFARequired: true, MFAToken: mfaToken, ExpiresIn: int64(h.cfg.MFATokenTTL.Seconds())})
+		return
+	}
+
+	if err := attempt.Succeed(ctx); err != nil {
+		log.Println("reset percobaan login gagal:", err)
+	}
+	h.completeLogin(c, u, scope)
+}
+
+// @Summary Verify MFA login
+// @Description Exchange the MFA challenge token from /api/users/login plus a TOTP code or recovery code for a token pair. Each challenge token allows a single attempt: after a wrong code the user logs in with the password again. Failures count towards login throttling.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param mfaRequest body mfaLoginReq true "Challenge token and code" example({"mfa_token": "eyJhbG...", "code": "123456"})
+// @Success 200 {object} tokenPairResp
+// @Failure 400,401,403 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Router /api/users/login/mfa [post]
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req mfaLoginReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	claims, err := appauth.ParseMFAToken(h.cfg, h.keys, req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "token MFA tidak valid atau kedaluwarsa"})
+		return
+	}
+	var u user.User
+	if err := h.db.First(&u, "id = ?", claims.UserID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "token MFA tidak valid atau kedaluwarsa"})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses login"})
 		return
 	}
 
-	// Verifikasi password: coba bcrypt, jika gagal coba plain match sebagai fallback dev
-	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(req.Password)); err != nil {
-		if u.Password != req.Password {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "username atau password salah"})
+	ctx := c.Request.Context()
+	attempt, wait, err := h.guard.Reserve(ctx, u.Username, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses login"})
+		return
+	}
+	if wait > 0 {
+		tooManyAttempts(c, wait)
+		return
+	}
+	defer releaseAttempt(ctx, attempt)
+	if u.Disabled {
+		c.JSON(http.StatusForbidden, gin.H{"message": "akun dinonaktifkan"})
+		return
+	}
+	if !u.MFAEnabled {
+		// MFA direset admin setelah challenge dibuat; login ulang dengan password
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "token MFA tidak valid atau kedaluwarsa"})
+		return
+	}
+
+	// token challenge diklaim sebelum kode dicek: setiap challenge hanya mendapat satu tebakan,
+	// dan request paralel dengan token yang sama tidak bisa menebak bersamaan
+	first, err := h.ts.UseMFAChallenge(ctx, claims.ID, time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses login"})
+		return
+	}
+	if !first {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "token MFA tidak valid atau kedaluwarsa"})
+		return
+	}
+
+	ok, recovery, err := verifyMFACode(h.db.WithContext(ctx), h.box, u, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses login"})
+		return
+	}
+	if !ok {
+		h.audit(c, auditEntry{Action: audit.ActionLoginFailed, EntityType: entityUser, EntityID: u.ID.String(), After: gin.H{"username": u.Username, "reason": "invalid_mfa_code"}})
+		h.loginFailed(c, attempt, u.Username, &u)
+		return
+	}
+	if recovery {
+		h.audit(c, auditEntry{Action: audit.ActionMFARecovery, EntityType: entityUser, EntityID: u.ID.String(), ActorID: u.ID})
+	}
+	if err := attempt.Succeed(ctx); err != nil {
+		log.Println("reset percobaan login gagal:", err)
+	}
+	// role bisa berubah sejak challenge dibuat
+	scope := user.ParseScopes(claims.Scope)
+	if len(scope) > 0 {
+		if scope = scope.Intersect(user.RoleScopes(u.Role)); len(scope) == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"message": "scope yang diminta tidak lagi diizinkan untuk role Anda"})
 			return
 		}
 	}
+	h.completeLogin(c, u, scope)
+}
 
-	// Buat token
-	at, err := appauth.GenerateAccessToken(h.cfg, u.ID)
+// completeLogin buat & simpan token pair lalu kirim response login.
+// scope kosong berarti semua scope role; scope yang dikurangi ikut disimpan di RT sebagai batas saat refresh.
+func (h *AuthHandler) completeLogin(c *gin.Context, u user.User, scope user.Scopes) {
+	// setiap login memulai family RT (sesi) baru
+	family := uuid.New().String()
+	granted := scope
+	if len(granted) == 0 {
+		granted = user.RoleScopes(u.Role)
+	}
+	sub, err := tokenSubject(h.db.WithContext(c.Request.Context()), u, family, granted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses login"})
+		return
+	}
+	at, atJTI, err := appauth.GenerateAccessToken(h.cfg, h.keys, sub)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat access token"})
 		return
 	}
-	rt, jti, err := appauth.GenerateRefreshToken(h.cfg, u.ID)
+	if err := h.ts.TrackAccessToken(c.Request.Context(), u.ID, family, atJTI, h.cfg.AccessTokenTTL); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menyimpan access token"})
+		return
+	}
+	rt, jti, err := appauth.GenerateRefreshToken(h.cfg, h.keys, u.ID, appauth.RefreshGrant{Family: family, Scope: scope.String()})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat refresh token"})
 		return
 	}
 
 	// Simpan RT ke Redis
-	if err := h.ts.SaveRefreshToken(c.Request.Context(), u.ID, jti, h.cfg.RefreshTokenTTL); err != nil {
+	if err := h.ts.SaveRefreshToken(c.Request.Context(), u.ID, jti, family, sessionMeta(c), h.cfg.RefreshTokenTTL); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menyimpan refresh token"})
 		return
 	}
 
+	h.audit(c, auditEntry{Action: audit.ActionLogin, EntityType: entityUser, EntityID: u.ID.String(), ActorID: u.ID})
+
 	c.JSON(http.StatusOK, tokenPairResp{
-		AccessToken:      at,
-		RefreshToken:     rt,
-		TokenType:        "Bearer",
-		ExpiresIn:        int64(h.cfg.AccessTokenTTL.Seconds()),
-		RefreshExpiresIn: int64(h.cfg.RefreshTokenTTL.Seconds()),
-		UserID:           u.ID.String(),
-		Username:         u.Username,
+		AccessToken:           at,
+		RefreshToken:          rt,
+		TokenType:             "Bearer",
+		ExpiresIn:             int64(h.cfg.AccessTokenTTL.Seconds()),
+		RefreshExpiresIn:      int64(h.cfg.RefreshTokenTTL.Seconds()),
+		UserID:                u.ID.String(),
+		Username:              u.Username,
+		Role:                  u.Role,
+		Scope:                 sub.Scope,
+		PasswordResetRequired: u.PasswordResetRequired,
+		MFAEnrollRequired:     sub.MFAEnroll,
 	})
 }
 
 // @Summary Refresh token
-// @Description Get new access token using refresh token
+// @Description Get new access token using refresh token. An optional space-separated scope narrows the new access token; it cannot exceed the scope granted at login, and scopes the user's current role no longer has are dropped. The refresh token is rotated; presenting an already rotated refresh token again revokes every token issued from the same login and all access tokens of the user.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param refreshRequest body refreshReq true "Refresh token" example({"refresh_token": "eyJhbG..."})
 // @Success 200 {object} tokenPairResp
-// @Failure 400,401 {object} gin.H "example={'message':'refresh token tidak valid'}"
+// @Failure 400,401,403 {object} gin.H "example={'message':'refresh token tidak valid'}"
 // @Router /api/users/refresh [post]
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var req refreshReq
@@ -121,56 +326,115 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	}
 
 	// Parse dan validasi refresh token
-	claims, err := appauth.ParseToken(h.cfg, req.RefreshToken)
+	claims, err := appauth.ParseRefreshToken(h.cfg, h.keys, req.RefreshToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "refresh token tidak valid"})
 		return
 	}
 
-	// Pastikan RT ini masih valid di Redis
-	valid, err := h.ts.VerifyRefreshToken(c.Request.Context(), claims.UserID, claims.ID)
+	// RT milik client OAuth hanya bisa dirotasi lewat /api/oauth/token
+	if claims.ClientID != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "refresh token tidak valid"})
+		return
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi refresh token"})
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "token tidak valid"})
 		return
 	}
-	if !valid {
+	// RT yang dibuat sebelum ada family dipindah ke family baru saat rotasi pertama
+	family := claims.Family
+	if family == "" {
+		family = uuid.New().String()
+	}
+
+	// scope diperiksa sebelum rotasi agar permintaan yang salah tidak menghabiskan RT
+	limit := user.ParseScopes(claims.Scope)
+	if len(limit) == 0 {
+		limit = user.AllScopes() // dibatasi role saat ini setelah user dibaca
+	}
+	requested := user.ParseScopes(req.Scope)
+	if !limit.Contains(requested) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "scope melebihi scope yang diberikan saat login"})
+		return
+	}
+	if len(requested) == 0 {
+		requested = limit
+	}
+
+	// Rotasi RT: yang lama diganti yang baru secara atomik; RT lama yang dipakai ulang mencabut seluruh family.
+	// Scope RT tetap sama untuk seluruh family.
+	newRT, newJTI, err := appauth.GenerateRefreshToken(h.cfg, h.keys, userID, appauth.RefreshGrant{Family: family, Scope: claims.Scope})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat refresh token"})
+		return
+	}
+	ctx := c.Request.Context()
+	err = h.ts.RotateRefreshToken(ctx, userID, family, claims.ID, newJTI, sessionMeta(c), h.cfg.RefreshTokenTTL)
+	switch {
+	case errors.Is(err, appauth.ErrRefreshTokenReused):
+		h.refreshReused(c, userID, family)
+		return
+	case errors.Is(err, appauth.ErrRefreshTokenInvalid):
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "refresh token sudah tidak berlaku"})
 		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi refresh token"})
+		return
 	}
 
-	// Rotasi RT: hapus yang lama, buat yang baru
-	if err := h.ts.RevokeRefreshToken(c.Request.Context(), claims.UserID, claims.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencabut refresh token"})
+	// role dibaca ulang agar perubahan role berlaku saat refresh berikutnya
+	var u user.User
+	if err := h.db.First(&u, "id = ?", userID).Error; err != nil {
+		h.ts.RevokeRefreshToken(ctx, userID.String(), newJTI)
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "user tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses refresh token"})
+		return
+	}
+	if u.Disabled {
+		h.ts.RevokeRefreshToken(ctx, userID.String(), newJTI)
+		c.JSON(http.StatusForbidden, gin.H{"message": "akun dinonaktifkan"})
 		return
 	}
 
-	userID, err := uuid.Parse(claims.UserID)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "token tidak valid"})
+	granted := requested.Intersect(user.RoleScopes(u.Role))
+	if len(granted) == 0 {
+		h.ts.RevokeRefreshToken(ctx, userID.String(), newJTI)
+		c.JSON(http.StatusForbidden, gin.H{"message": "scope yang diminta tidak lagi diizinkan untuk role Anda"})
 		return
 	}
 
-	newAT, err := appauth.GenerateAccessToken(h.cfg, userID)
+	sub, err := tokenSubject(h.db.WithContext(c.Request.Context()), u, family, granted)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat access token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses refresh token"})
 		return
 	}
-	newRT, newJTI, err := appauth.GenerateRefreshToken(h.cfg, userID)
+	newAT, atJTI, err := appauth.GenerateAccessToken(h.cfg, h.keys, sub)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat refresh token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat access token"})
 		return
 	}
-	if err := h.ts.SaveRefreshToken(c.Request.Context(), userID, newJTI, h.cfg.RefreshTokenTTL); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menyimpan refresh token"})
+	if err := h.ts.TrackAccessToken(ctx, userID, family, atJTI, h.cfg.AccessTokenTTL); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menyimpan access token"})
 		return
 	}
 
+	h.audit(c, auditEntry{Action: audit.ActionRefresh, EntityType: entityUser, EntityID: userID.String(), ActorID: userID})
+
 	c.JSON(http.StatusOK, tokenPairResp{
-		AccessToken:      newAT,
-		RefreshToken:     newRT,
-		TokenType:        "Bearer",
-		ExpiresIn:        int64(h.cfg.AccessTokenTTL.Seconds()),
-		RefreshExpiresIn: int64(h.cfg.RefreshTokenTTL.Seconds()),
+		AccessToken:           newAT,
+		RefreshToken:          newRT,
+		TokenType:             "Bearer",
+		ExpiresIn:             int64(h.cfg.AccessTokenTTL.Seconds()),
+		RefreshExpiresIn:      int64(h.cfg.RefreshTokenTTL.Seconds()),
+		Role:                  u.Role,
+		Scope:                 sub.Scope,
+		PasswordResetRequired: u.PasswordResetRequired,
+		MFAEnrollRequired:     sub.MFAEnroll,
 	})
 }
 
@@ -179,7 +443,7 @@ type logoutReq struct {
 }
 
 // @Summary Logout user
-// @Description Logout and invalidate tokens
+// @Description Logout and invalidate tokens. The access token used for the request is revoked immediately; without refresh_token every refresh and access token of the user is revoked.
 // @Tags auth
 // @Security BearerAuth
 // @Accept json
@@ -199,18 +463,25 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	var req logoutReq
 	_ = c.ShouldBindJSON(&req) // optional body
 
+	// access token yang dipakai untuk logout langsung tidak berlaku lagi
+	if err := h.ts.DenyAccessToken(c.Request.Context(), c.GetString("jti"), time.Until(c.GetTime("tokenExp"))); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal logout"})
+		return
+	}
+
 	if req.RefreshToken == "" {
-		// Revoke semua token user
-		if err := h.ts.RevokeAllRefreshTokens(c.Request.Context(), userIDStr); err != nil {
+		// Revoke semua token user, termasuk access token di perangkat lain
+		if err := h.ts.RevokeAllTokens(c.Request.Context(), userIDStr); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal logout"})
 			return
 		}
+		h.audit(c, auditEntry{Action: audit.ActionLogout, EntityType: entityUser, EntityID: userIDStr, After: gin.H{"scope": "all"}})
 		c.JSON(http.StatusOK, gin.H{"message": "logout berhasil"})
 		return
 	}
 
 	// Jika disediakan refresh_token tertentu, revoke token tersebut
-	claims, err := appauth.ParseToken(h.cfg, req.RefreshToken)
+	claims, err := appauth.ParseRefreshToken(h.cfg, h.keys, req.RefreshToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "refresh token tidak valid"})
 		return
@@ -225,6 +496,127 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
+	h.audit(c, auditEntry{Action: audit.ActionLogout, EntityType: entityUser, EntityID: userIDStr, After: gin.H{"scope": "single"}})
 	c.JSON(http.StatusOK, gin.H{"message": "logout berhasil"})
 }
 
+// refreshReused tanggapi RT yang sudah dirotasi tetapi dipakai lagi, lihat revokeReusedFamily
+func (h *AuthHandler) refreshReused(c *gin.Context, userID uuid.UUID, family string) {
+	revokeReusedFamily(c, h.db, h.ts, userID, family, "")
+	c.JSON(http.StatusUnauthorized, gin.H{"message": "refresh token sudah pernah dipakai, sesi dicabut"})
+}
+
+// revokeReusedFamily RT yang sudah dirotasi dipakai lagi: kemungkinan RT dicuri.
+// Family sudah dicabut oleh TokenStore; access token user ikut dicabut karena bisa jadi milik penyerang.
+func revokeReusedFamily(c *gin.Context, db *gorm.DB, ts *appauth.TokenStore, userID uuid.UUID, family, clientID string) {
+	log.Printf("SECURITY refresh token reuse: user=%s family=%s client=%s ip=%s", userID, family, clientID, c.ClientIP())
+	if err := ts.DenyAllAccessTokens(c.Request.Context(), userID.String()); err != nil {
+		log.Println("cabut access token setelah reuse refresh token:", err)
+	}
+	after := gin.H{"family": family, "user_agent": c.Request.UserAgent()}
+	if clientID != "" {
+		after["client_id"] = clientID
+	}
+	if err := recordAudit(db.WithContext(c.Request.Context()), c, auditEntry{Action: audit.ActionTokenReuse, EntityType: entityUser, EntityID: userID.String(), After: after}); err != nil {
+		log.Println("audit auth event gagal:", err)
+	}
+}
+
+// loginFailed catat percobaan gagal ke LoginGuard lalu kirim 401, atau 429 jika username/IP baru saja dikunci.
+// u nil jika username tidak terdaftar.
+func (h *AuthHandler) loginFailed(c *gin.Context, attempt *appauth.LoginAttempt, username string, u *user.User) {
+	if res := recordLoginFailure(c, h.db, attempt, username, u); res.UserLocked || res.IPLocked {
+		tooManyAttempts(c, res.RetryAfter)
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"message": "username atau password salah"})
+}
+
+// recordLoginFailure catat percobaan gagal ke LoginGuard; jika username/IP baru saja dikunci,
+// lockout dicatat ke log dan audit. Dipakai login API dan halaman authorize OAuth.
+func recordLoginFailure(c *gin.Context, db *gorm.DB, attempt *appauth.LoginAttempt, username string, u *user.User) appauth.LoginFailure {
+	res, err := attempt.Fail(c.Request.Context())
+	if err != nil {
+		log.Println("catat percobaan login gagal:", err)
+	}
+	if res.UserLocked || res.IPLocked {
+		log.Printf("SECURITY login lockout: username=%q ip=%s user_locked=%t ip_locked=%t duration=%s",
+			username, c.ClientIP(), res.UserLocked, res.IPLocked, res.RetryAfter)
+		e := auditEntry{Action: audit.ActionLockout, EntityType: entityUser, After: gin.H{
+			"username":    username,
+			"user_locked": res.UserLocked,
+			"ip_locked":   res.IPLocked,
+			"duration":    res.RetryAfter.String(),
+		}}
+		if u != nil {
+			e.EntityID = u.ID.String()
+		}
+		if err := recordAudit(db.WithContext(c.Request.Context()), c, e); err != nil {
+			log.Println("audit auth event gagal:", err)
+		}
+	}
+	return res
+}
+
+// releaseAttempt kembalikan percobaan yang belum dicatat gagal/berhasil (mis. menunggu kode MFA
+// atau request gagal diproses); dipanggil lewat defer setelah Reserve
+func releaseAttempt(ctx context.Context, attempt *appauth.LoginAttempt) {
+	if err := attempt.Release(ctx); err != nil {
+		log.Println("kembalikan percobaan login gagal:", err)
+	}
+}
+
+// tooManyAttempts kirim 429 untuk login yang sedang dibatasi
+func tooManyAttempts(c *gin.Context, wait time.Duration) {
+	tooManyRequests(c, wait, "terlalu banyak percobaan login, coba lagi nanti")
+}
+
+// tooManyRequests kirim 429 dengan header Retry-After (detik, dibulatkan ke atas)
+func tooManyRequests(c *gin.Context, wait time.Duration, message string) {
+	secs := int64(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.FormatInt(secs, 10))
+	c.JSON(http.StatusTooManyRequests, gin.H{"message": message, "retry_after": secs})
+}
+
+// upgradeHash simpan ulang hash dengan algoritma/parameter terbaru; kegagalan tidak menggagalkan login
+func (h *AuthHandler) upgradeHash(c *gin.Context, u user.User, plain string) {
+	hashed, err := h.passwords.Hash(plain)
+	if err == nil {
+		// hanya ganti jika hash belum diubah request lain sejak dibaca
+		err = h.db.WithContext(c.Request.Context()).Model(&user.User{}).
+			Where("id = ? AND password = ?", u.ID, u.Password).
+			UpdateColumn("password", hashed).Error
+	}
+	if err != nil {
+		log.Println("upgrade hash password gagal:", err)
+	}
+}
+
+// tokenSubject data user yang dimasukkan ke access token, termasuk kewajiban MFA dari kebijakan role,
+// sesi (family RT) asal token dan scope yang diberikan
+func tokenSubject(db *gorm.DB, u user.User, session string, scope user.Scopes) (appauth.Subject, error) {
+	required, err := mfaRequired(db, u.Role)
+	if err != nil {
+		return appauth.Subject{}, err
+	}
+	return appauth.Subject{
+		UserID:        u.ID,
+		Role:          u.Role,
+		PasswordReset: u.PasswordResetRequired,
+		MFAEnroll:     required && !u.MFAEnabled,
+		SessionID:     session,
+		Scope:         scope.String(),
+	}, nil
+}
+
+// sessionMeta data perangkat dari request untuk daftar sesi
+func sessionMeta(c *gin.Context) appauth.SessionMeta {
+	return appauth.SessionMeta{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+// audit catat event auth; kegagalan menulis audit tidak menggagalkan request
+func (h *AuthHandler) audit(c *gin.Context, e auditEntry) {
+	if err := recordAudit(h.db.WithContext(c.Request.Context()), c, e); err != nil {
+		log.Println("audit auth event gagal:", err)
+	}
+}
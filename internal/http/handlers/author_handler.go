@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/author"
+	"github.com/qullDev/book_API/internal/domain/book"
+	"gorm.io/gorm"
+)
+
+type AuthorHandler struct {
+	db *gorm.DB
+}
+
+func NewAuthorHandler(db *gorm.DB) *AuthorHandler {
+	return &AuthorHandler{db: db}
+}
+
+func (h *AuthorHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.POST("", h.Create)
+	rg.GET("/:id", h.Detail)
+	rg.PUT("/:id", h.Update)
+	rg.DELETE("/:id", h.Delete)
+}
+
+type createAuthorReq struct {
+	Name string `json:"name" binding:"required,min=1,max=150"`
+	Bio  string `json:"bio"`
+}
+
+type updateAuthorReq struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=150"`
+	Bio  *string `json:"bio"`
+}
+
+var authorSortFields = map[string]sortField{
+	"name":       {Column: "name", Type: "text"},
+	"created_at": {Column: "created_at", Type: "timestamptz"},
+}
+
+// @Summary List authors
+// @Description Get paginated list of authors
+// @Tags authors
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param q query string false "Filter by name (case-insensitive, partial)"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Param sort query string false "Sort field, prefix with - for descending" Enums(name, -name, created_at, -created_at)
+// @Success 200 {object} pageResp
+// @Failure 400 {object} gin.H
+// @Router /api/authors [get]
+func (h *AuthorHandler) List(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	order, err := parseSort(c.Query("sort"), authorSortFields, "name")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	q := db.Model(&author.Author{})
+	if v := c.Query("q"); v != "" {
+		q = q.Where("name ILIKE ?", "%"+v+"%")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data author"})
+		return
+	}
+	items := []author.Author{}
+	if err := q.Scopes(withUsers).Order(order).Order("id asc").Limit(p.PageSize).Offset(p.Offset).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data author"})
+		return
+	}
+	c.JSON(http.StatusOK, newPageResp(c, p, total, items))
+}
+
+// @Summary Create author
+// @Description Create a new author
+// @Tags authors
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param author body createAuthorReq true "Author data" example({"name": "Alan A. A. Donovan", "bio": "Member of Google's Go team"})
+// @Success 201 {object} map[string]author.Author
+// @Failure 400 {object} gin.H "example={'message':'payload tidak valid'}"
+// @Router /api/authors [post]
+func (h *AuthorHandler) Create(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req createAuthorReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	item := author.Author{Name: req.Name, Bio: req.Bio}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionCreate, EntityType: entityAuthor, EntityID: item.ID.String(), After: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menambahkan author"})
+		return
+	}
+	if err := db.Scopes(withUsers).First(&item, "id = ?", item.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data author"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+// @Summary Get author detail
+// @Description Get detail of an author
+// @Tags authors
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Author ID" format(uuid)
+// @Success 200 {object} map[string]author.Author
+// @Failure 400,404 {object} gin.H
+// @Router /api/authors/{id} [get]
+func (h *AuthorHandler) Detail(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	var item author.Author
+	if err := db.Scopes(withUsers).First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "author tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil detail author"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// @Summary Update author
+// @Description Update an existing author
+// @Tags authors
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Author ID" format(uuid)
+// @Param author body updateAuthorReq true "Updated author data"
+// @Success 200 {object} map[string]author.Author
+// @Failure 400,404 {object} gin.H
+// @Router /api/authors/{id} [put]
+func (h *AuthorHandler) Update(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	var req updateAuthorReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	var item author.Author
+	if err := db.First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "author tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data author"})
+		return
+	}
+	before := item
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Bio != nil {
+		item.Bio = *req.Bio
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionUpdate, EntityType: entityAuthor, EntityID: item.ID.String(), Before: before, After: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengupdate author"})
+		return
+	}
+	if err := db.Scopes(withUsers).First(&item, "id = ?", item.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data author"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// @Summary Delete author
+// @Description Delete an author that is not linked to any book
+// @Tags authors
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Author ID" format(uuid)
+// @Success 200 {object} gin.H
+// @Failure 400,404,409 {object} gin.H
+// @Router /api/authors/{id} [delete]
+func (h *AuthorHandler) Delete(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	var used int64
+	if err := db.Model(&book.BookAuthor{}).Where("author_id = ?", id).Count(&used).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus author"})
+		return
+	}
+	if used > 0 {
+		c.JSON(http.StatusConflict, gin.H{"message": "author masih terhubung dengan buku", "book_count": used})
+		return
+	}
+	var item author.Author
+	if err := db.First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "author tidak tersedia"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus author"})
+		return
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&author.Author{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionDelete, EntityType: entityAuthor, EntityID: id.String(), Before: item})
+	})
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		// buku baru dihubungkan ke author ini di antara pengecekan dan penghapusan
+		if err := db.Model(&book.BookAuthor{}).Where("author_id = ?", id).Count(&used).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus author"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"message": "author masih terhubung dengan buku", "book_count": used})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus author"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "author berhasil dihapus"})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/author"
+	"github.com/qullDev/book_API/internal/domain/book"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bookAuthorReq satu author pada request buku; urutan array menentukan posisi
+type bookAuthorReq struct {
+	AuthorID uuid.UUID `json:"author_id" binding:"required"`
+	Role     string    `json:"role" binding:"omitempty,oneof=primary co_author translator editor"`
+}
+
+// bookAuthorError kesalahan input author yang dilaporkan sebagai 400
+type bookAuthorError string
+
+func (e bookAuthorError) Error() string { return string(e) }
+
+// toBookAuthors validasi author request lalu ubah menjadi baris join berurutan.
+// Role kosong: entry pertama jadi primary, sisanya co_author.
+func toBookAuthors(tx *gorm.DB, reqs []bookAuthorReq) ([]book.BookAuthor, error) {
+	rows := make([]book.BookAuthor, 0, len(reqs))
+	ids := make([]uuid.UUID, 0, len(reqs))
+	seen := map[string]bool{}
+	primaries := 0
+	for i, r := range reqs {
+		role := r.Role
+		if role == "" {
+			role = book.RoleCoAuthor
+			if i == 0 {
+				role = book.RolePrimary
+			}
+		}
+		key := r.AuthorID.String() + "/" + role
+		if seen[key] {
+			return nil, bookAuthorError("author dengan role yang sama tidak boleh duplikat")
+		}
+		seen[key] = true
+		if role == book.RolePrimary {
+			primaries++
+		}
+		rows = append(rows, book.BookAuthor{AuthorID: r.AuthorID, Role: role, Position: i})
+		ids = append(ids, r.AuthorID)
+	}
+	if primaries > 1 {
+		return nil, bookAuthorError("hanya boleh ada satu primary author")
+	}
+	if len(ids) == 0 {
+		return rows, nil
+	}
+
+	var found int64
+	if err := tx.Model(&author.Author{}).Where("id IN ?", ids).Distinct("id").Count(&found).Error; err != nil {
+		return nil, err
+	}
+	unique := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		unique[id] = true
+	}
+	if int(found) != len(unique) {
+		return nil, bookAuthorError("author tidak ditemukan")
+	}
+	return rows, nil
+}
+
+// replaceBookAuthors ganti seluruh relasi author sebuah buku
+func replaceBookAuthors(tx *gorm.DB, bookID uuid.UUID, rows []book.BookAuthor) error {
+	if err := tx.Where("book_id = ?", bookID).Delete(&book.BookAuthor{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	for i := range rows {
+		rows[i].BookID = bookID
+	}
+	return tx.Omit(clause.Associations).Create(&rows).Error
+}
@@ -1,12 +1,22 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/config"
+	"github.com/qullDev/book_API/internal/domain/audit"
 	"github.com/qullDev/book_API/internal/domain/book"
+	"github.com/qullDev/book_API/internal/domain/category"
+	"github.com/qullDev/book_API/internal/pkg/isbn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -15,56 +25,308 @@ const (
 )
 
 type BookHandler struct {
-	db *gorm.DB
+	db  *gorm.DB
+	cfg *config.Config
 }
 
-func NewBookHandler(db *gorm.DB) *BookHandler {
-	return &BookHandler{db: db}
+func NewBookHandler(db *gorm.DB, cfg *config.Config) *BookHandler {
+	return &BookHandler{db: db, cfg: cfg}
 }
 
 func (h *BookHandler) Register(rg *gin.RouterGroup) {
 	rg.GET("", h.List)
 	rg.POST("", h.Create)
+	rg.GET("/search", h.Search)
+	rg.GET("/isbn/:isbn", h.DetailByISBN)
+	rg.GET("/trash", h.Trash)
 	rg.GET("/:id", h.Detail)
 	rg.PUT("/:id", h.Update)
 	rg.DELETE("/:id", h.Delete)
+	rg.POST("/:id/restore", h.Restore)
+	rg.DELETE("/:id/purge", h.Purge)
+	rg.GET("/:id/revisions", h.Revisions)
+	rg.GET("/:id/revisions/diff", h.RevisionDiff)
+	rg.GET("/:id/revisions/:rev", h.RevisionDetail)
+	rg.POST("/:id/revisions/:rev/restore", h.RestoreRevision)
 }
 
 type createBookReq struct {
 	Title       string    `json:"title" binding:"required,max=200"`
+	ISBN        string    `json:"isbn"` // ISBN-10 atau ISBN-13, disimpan sebagai ISBN-13
 	CategoryID  uuid.UUID `json:"category_id" binding:"required"`
 	Description string    `json:"description"`
 	ImageURL    string    `json:"image_url"`
 	ReleaseYear int       `json:"release_year" binding:"required"`
 	Price       float64   `json:"price" binding:"required"`
 	TotalPage   int       `json:"total_page" binding:"required"`
+	// Authors urutan menentukan posisi; role kosong = primary untuk entry pertama
+	Authors []bookAuthorReq `json:"authors" binding:"omitempty,dive"`
 }
 
 type updateBookReq struct {
 	Title       *string    `json:"title" binding:"omitempty,max=200"`
+	ISBN        *string    `json:"isbn"` // string kosong menghapus ISBN
 	CategoryID  *uuid.UUID `json:"category_id"`
 	Description *string    `json:"description"`
 	ImageURL    *string    `json:"image_url"`
 	ReleaseYear *int       `json:"release_year"`
 	Price       *float64   `json:"price"`
 	TotalPage   *int       `json:"total_page"`
+	// Authors jika dikirim menggantikan seluruh daftar author buku
+	Authors *[]bookAuthorReq `json:"authors" binding:"omitempty,dive"`
+}
+
+// bookSortFields whitelist kolom yang boleh dipakai pada param sort
+var bookSortFields = map[string]sortField{
+	"title":        {Column: "title", Type: "text"},
+	"price":        {Column: "price", Type: "numeric"},
+	"release_year": {Column: "release_year", Type: "bigint"},
+	"total_page":   {Column: "total_page", Type: "bigint"},
+	"created_at":   {Column: "created_at", Type: "timestamptz"},
+}
+
+// bookSortValue nilai sort key sebuah buku untuk disimpan di cursor
+func bookSortValue(b book.Book, f sortField) string {
+	switch f.Column {
+	case "price":
+		return strconv.FormatFloat(b.Price, 'f', -1, 64)
+	case "release_year":
+		return strconv.Itoa(b.ReleaseYear)
+	case "total_page":
+		return strconv.Itoa(b.TotalPage)
+	case "created_at":
+		return b.CreatedAt.Format(time.RFC3339Nano)
+	default:
+		return b.Title
+	}
 }
 
 // @Summary List all books
-// @Description Get list of all books
+// @Description Get paginated list of books with optional filters and sorting
 // @Tags books
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Success 200 {object} map[string][]book.Book
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Param mode query string false "Set to cursor to start keyset pagination" Enums(cursor)
+// @Param cursor query string false "Opaque cursor from next_cursor of the previous page"
+// @Param limit query int false "Alternative to page_size, used with offset"
+// @Param offset query int false "Alternative to page, used with limit"
+// @Param category_id query string false "Filter by category ID" format(uuid)
+// @Param min_release_year query int false "Minimum release year"
+// @Param max_release_year query int false "Maximum release year"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param thickness query string false "Filter by thickness" Enums(tipis, tebal)
+// @Param sort query string false "Sort field, prefix with - for descending" Enums(title, -title, price, -price, release_year, -release_year, total_page, -total_page, created_at, -created_at)
+// @Success 200 {object} pageResp "Offset mode; cursor mode responds with cursorResp"
+// @Failure 400 {object} gin.H
 // @Router /api/books [get]
 func (h *BookHandler) List(c *gin.Context) {
-	var items []book.Book
-	if err := h.db.Order("title asc").Find(&items).Error; err != nil {
+	db := h.db.WithContext(c.Request.Context())
+	q, err := applyBookFilters(c, db.Model(&book.Book{}))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	cp, cursorMode, err := parseCursorPage(c, h.cfg.CursorSecret, bookSortFields, "title")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if cursorMode {
+		listBooksByCursor(c, h.cfg, q, cp)
+		return
+	}
+
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	order, err := parseSort(c.Query("sort"), bookSortFields, "title")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data buku"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": items})
+
+	items := []book.Book{}
+	if err := q.Scopes(withBookRelations).Order(order).Order("id asc").Limit(p.PageSize).Offset(p.Offset).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data buku"})
+		return
+	}
+	c.JSON(http.StatusOK, newPageResp(c, p, total, items))
+}
+
+// listBooksByCursor menjalankan keyset pagination untuk query buku yang sudah difilter
+func listBooksByCursor(c *gin.Context, cfg *config.Config, q *gorm.DB, cp cursorPage) {
+	items := []book.Book{}
+	if err := cp.apply(q).Scopes(withBookRelations).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data buku"})
+		return
+	}
+	hasMore := len(items) > cp.PageSize
+	var lastValue string
+	var lastID uuid.UUID
+	if hasMore {
+		items = items[:cp.PageSize]
+		last := items[len(items)-1]
+		lastValue, lastID = bookSortValue(last, cp.Field), last.ID
+	}
+	resp, err := newCursorResp(c, cfg.CursorSecret, cp, items, hasMore, lastValue, lastID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat cursor"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// bookSearchHit hasil full-text search: buku + skor dan potongan teks yang di-highlight
+type bookSearchHit struct {
+	book.Book
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// @Summary Search books
+// @Description Full-text search over book title and description with ranking, prefix matching and highlighted snippets
+// @Tags books
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param q query string true "Search terms, partial words are matched as prefixes"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Param category_id query string false "Filter by category ID" format(uuid)
+// @Success 200 {object} pageResp
+// @Failure 400 {object} gin.H
+// @Router /api/books/search [get]
+func (h *BookHandler) Search(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	tsq := prefixTSQuery(c.Query("q"))
+	if tsq == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "q wajib diisi"})
+		return
+	}
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	q := db.Model(&book.Book{}).
+		Joins("CROSS JOIN to_tsquery('simple', ?) AS q(query)", tsq).
+		Where("books.search_vector @@ q.query")
+	q, err = applyBookFilters(c, q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencari buku"})
+		return
+	}
+
+	hits := []bookSearchHit{}
+	err = q.Select(`books.*,
+			ts_rank(books.search_vector, q.query) AS rank,
+			ts_headline('simple', books.title, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS title_highlight,
+			ts_headline('simple', coalesce(books.description, ''), q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10') AS snippet`).
+		Order("rank desc").Order("books.id asc").
+		Limit(p.PageSize).Offset(p.Offset).
+		Scan(&hits).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencari buku"})
+		return
+	}
+
+	// Scan tidak mendukung Preload, jadi relasi dimuat lewat query kedua
+	ids := make([]uuid.UUID, len(hits))
+	for i := range hits {
+		ids[i] = hits[i].ID
+	}
+	var full []book.Book
+	if err := db.Scopes(withBookRelations).Where("id IN ?", ids).Find(&full).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencari buku"})
+		return
+	}
+	byID := make(map[uuid.UUID]book.Book, len(full))
+	for _, b := range full {
+		byID[b.ID] = b
+	}
+	for i := range hits {
+		hits[i].Book = byID[hits[i].ID]
+	}
+	c.JSON(http.StatusOK, newPageResp(c, p, total, hits))
+}
+
+// prefixTSQuery ubah input bebas menjadi tsquery "kata1:* & kata2:*".
+// Hanya huruf dan angka yang dipertahankan sehingga aman dari sintaks tsquery.
+func prefixTSQuery(raw string) string {
+	words := strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// applyBookFilters menerapkan filter query string ke query buku
+func applyBookFilters(c *gin.Context, q *gorm.DB) (*gorm.DB, error) {
+	if v := c.Query("category_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, errors.New("category_id tidak valid")
+		}
+		q = q.Where("category_id = ?", id)
+	}
+	if v := c.Query("min_release_year"); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("min_release_year tidak valid")
+		}
+		q = q.Where("release_year >= ?", year)
+	}
+	if v := c.Query("max_release_year"); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("max_release_year tidak valid")
+		}
+		q = q.Where("release_year <= ?", year)
+	}
+	minPrice, err := queryFloat(c, "min_price")
+	if err != nil {
+		return nil, errors.New("min_price tidak valid")
+	}
+	if minPrice != nil {
+		q = q.Where("price >= ?", *minPrice)
+	}
+	maxPrice, err := queryFloat(c, "max_price")
+	if err != nil {
+		return nil, errors.New("max_price tidak valid")
+	}
+	if maxPrice != nil {
+		q = q.Where("price <= ?", *maxPrice)
+	}
+	if v := c.Query("thickness"); v != "" {
+		if v != "tipis" && v != "tebal" {
+			return nil, errors.New("thickness harus tipis atau tebal")
+		}
+		q = q.Where("thickness = ?", v)
+	}
+	return q, nil
 }
 
 // @Summary Create new book
@@ -73,11 +335,13 @@ func (h *BookHandler) List(c *gin.Context) {
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param book body createBookReq true "Book data" example({"title":"The Go Programming Language","category_id":"550e8400-e29b-41d4-a716-446655440000","description":"Comprehensive guide to Go","image_url":"https://example.com/book.jpg","release_year":2020,"price":59.99,"total_page":150})
+// @Param book body createBookReq true "Book data" example({"title":"The Go Programming Language","isbn":"978-0-13-419044-0","category_id":"550e8400-e29b-41d4-a716-446655440000","description":"Comprehensive guide to Go","image_url":"https://example.com/book.jpg","release_year":2020,"price":59.99,"total_page":150,"authors":[{"author_id":"8d7f5c1e-3b0a-4e4f-9a51-2f6c1e7d9b10","role":"primary"}]})
 // @Success 201 {object} map[string]book.Book "example={'data':{'id':'550e8400-e29b-41d4-a716-446655440000','title':'The Go Programming Language','category_id':'550e8400-e29b-41d4-a716-446655440000','description':'Comprehensive guide to Go','release_year':2020,'price':59.99,'total_page':150,'thickness':'tebal'}}"
 // @Failure 400 {object} gin.H "example={'message':'payload tidak valid'}"
+// @Failure 409 {object} gin.H "example={'message':'isbn sudah terdaftar'}"
 // @Router /api/books [post]
 func (h *BookHandler) Create(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
 	var req createBookReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
@@ -87,9 +351,22 @@ func (h *BookHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "release_year harus antara 1980 sampai 2024"})
 		return
 	}
+	isbnVal, err := normalizeISBN(req.ISBN)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if taken, err := h.isbnTaken(isbnVal, uuid.Nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menambahkan buku"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"message": "isbn sudah terdaftar"})
+		return
+	}
 
 	item := book.Book{
 		Title:       req.Title,
+		ISBN:        isbnVal,
 		CategoryID:  req.CategoryID,
 		Description: req.Description,
 		ImageURL:    req.ImageURL,
@@ -99,7 +376,35 @@ func (h *BookHandler) Create(c *gin.Context) {
 		// Thickness akan diisi otomatis oleh hook BeforeCreate
 	}
 
-	if err := h.db.Create(&item).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		authors, err := toBookAuthors(tx, req.Authors)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return saveBookErr(err)
+		}
+		if err := replaceBookAuthors(tx, item.ID, authors); err != nil {
+			return err
+		}
+		if err := tx.Scopes(withBookRelations).First(&item, "id = ?", item.ID).Error; err != nil {
+			return err
+		}
+		if err := saveBookRevision(tx, nil, item, audit.ActionCreate); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionCreate, EntityType: entityBook, EntityID: item.ID.String(), After: item})
+	})
+	if err != nil {
+		var ae bookAuthorError
+		if errors.As(err, &ae) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": ae.Error()})
+			return
+		}
+		if errors.Is(err, errISBNTaken) {
+			c.JSON(http.StatusConflict, gin.H{"message": "isbn sudah terdaftar"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menambahkan buku", "error": err})
 		return
 	}
@@ -118,6 +423,7 @@ func (h *BookHandler) Create(c *gin.Context) {
 // @Failure 404 {object} gin.H
 // @Router /api/books/{id} [get]
 func (h *BookHandler) Detail(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -125,7 +431,7 @@ func (h *BookHandler) Detail(c *gin.Context) {
 		return
 	}
 	var item book.Book
-	if err := h.db.First(&item, "id = ?", id).Error; err != nil {
+	if err := db.Scopes(withBookRelations).First(&item, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"message": "buku tidak ditemukan"})
 			return
@@ -136,18 +442,84 @@ func (h *BookHandler) Detail(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": item})
 }
 
+// @Summary Get book by ISBN
+// @Description Look up a book by ISBN-10 or ISBN-13 (hyphens allowed)
+// @Tags books
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param isbn path string true "ISBN-10 or ISBN-13" example(978-0-13-419044-0)
+// @Success 200 {object} map[string]book.Book
+// @Failure 400,404 {object} gin.H
+// @Router /api/books/isbn/{isbn} [get]
+func (h *BookHandler) DetailByISBN(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	code, err := isbn.Normalize(c.Param("isbn"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	var item book.Book
+	if err := db.Scopes(withBookRelations).First(&item, "isbn = ?", code).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "buku tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil detail buku"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// normalizeISBN string kosong = tanpa ISBN (nil), selain itu wajib valid
+func normalizeISBN(raw string) (*string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	code, err := isbn.Normalize(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// errISBNTaken ISBN keburu dipakai request lain setelah cek isbnTaken; ditolak oleh unique index isbn
+var errISBNTaken = errors.New("isbn sudah terdaftar")
+
+// saveBookErr ubah pelanggaran unique index saat insert/update buku menjadi errISBNTaken
+// (isbn satu-satunya unique index di tabel books)
+func saveBookErr(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errISBNTaken
+	}
+	return err
+}
+
+// isbnTaken cek apakah ISBN sudah dipakai buku lain selain exceptID
+func (h *BookHandler) isbnTaken(code *string, exceptID uuid.UUID) (bool, error) {
+	if code == nil {
+		return false, nil
+	}
+	var count int64
+	// Unscoped: buku di trash tetap memegang unique index isbn
+	err := h.db.Unscoped().Model(&book.Book{}).Where("isbn = ? AND id <> ?", *code, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
 // @Summary Update book
-// @Description Update an existing book
+// @Description Update an existing book. Every update is stored as a new revision.
 // @Tags books
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "Book ID" example(550e8400-e29b-41d4-a716-446655440000)
-// @Param book body updateBookReq true "Updated book data" example({"title":"Updated Title","price":49.99})
+// @Param book body updateBookReq true "Updated book data" example({"title":"Updated Title","price":49.99,"authors":[{"author_id":"8d7f5c1e-3b0a-4e4f-9a51-2f6c1e7d9b10"},{"author_id":"0b9e2a44-6f1d-4c3b-8e7a-5d2c9f0a1b23","role":"translator"}]})
 // @Success 200 {object} map[string]book.Book
 // @Failure 400,404 {object} gin.H "example={'message':'buku tidak ditemukan'}"
+// @Failure 409 {object} gin.H "example={'message':'isbn sudah terdaftar'}"
 // @Router /api/books/{id} [put]
 func (h *BookHandler) Update(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -155,7 +527,7 @@ func (h *BookHandler) Update(c *gin.Context) {
 		return
 	}
 	var existing book.Book
-	if err := h.db.First(&existing, "id = ?", id).Error; err != nil {
+	if err := db.Scopes(withBookRelations).First(&existing, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"message": "buku tidak ditemukan"})
 			return
@@ -163,6 +535,11 @@ func (h *BookHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data buku"})
 		return
 	}
+	before, err := audit.Snapshot(existing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data buku"})
+		return
+	}
 
 	var req updateBookReq
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -173,6 +550,21 @@ func (h *BookHandler) Update(c *gin.Context) {
 	if req.Title != nil {
 		existing.Title = *req.Title
 	}
+	if req.ISBN != nil {
+		isbnVal, err := normalizeISBN(*req.ISBN)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		if taken, err := h.isbnTaken(isbnVal, existing.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengupdate buku"})
+			return
+		} else if taken {
+			c.JSON(http.StatusConflict, gin.H{"message": "isbn sudah terdaftar"})
+			return
+		}
+		existing.ISBN = isbnVal
+	}
 	if req.CategoryID != nil {
 		existing.CategoryID = *req.CategoryID
 	}
@@ -202,7 +594,37 @@ func (h *BookHandler) Update(c *gin.Context) {
 		}
 	}
 
-	if err := h.db.Save(&existing).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(&existing).Error; err != nil {
+			return saveBookErr(err)
+		}
+		if req.Authors != nil {
+			authors, err := toBookAuthors(tx, *req.Authors)
+			if err != nil {
+				return err
+			}
+			if err := replaceBookAuthors(tx, existing.ID, authors); err != nil {
+				return err
+			}
+		}
+		if err := tx.Scopes(withBookRelations).First(&existing, "id = ?", existing.ID).Error; err != nil {
+			return err
+		}
+		if err := saveBookRevision(tx, before, existing, audit.ActionUpdate); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionUpdate, EntityType: entityBook, EntityID: existing.ID.String(), Before: before, After: existing})
+	})
+	if err != nil {
+		var ae bookAuthorError
+		if errors.As(err, &ae) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": ae.Error()})
+			return
+		}
+		if errors.Is(err, errISBNTaken) {
+			c.JSON(http.StatusConflict, gin.H{"message": "isbn sudah terdaftar"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengupdate buku"})
 		return
 	}
@@ -210,7 +632,7 @@ func (h *BookHandler) Update(c *gin.Context) {
 }
 
 // @Summary Delete book
-// @Description Delete a book
+// @Description Move a book to the trash (soft delete)
 // @Tags books
 // @Security BearerAuth
 // @Accept json
@@ -221,21 +643,161 @@ func (h *BookHandler) Update(c *gin.Context) {
 // @Failure 404 {object} gin.H
 // @Router /api/books/{id} [delete]
 func (h *BookHandler) Delete(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
 		return
 	}
-	res := h.db.Delete(&book.Book{}, "id = ?", id)
-	if res.Error != nil {
+	var item book.Book
+	if err := db.Scopes(withBookRelations).First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "buku tidak tersedia"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus buku"})
 		return
 	}
-	if res.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"message": "buku tidak tersedia"})
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&book.Book{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionDelete, EntityType: entityBook, EntityID: id.String(), Before: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus buku"})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "buku berhasil dihapus"})
 }
 
+// @Summary List trashed books
+// @Description Get paginated list of soft-deleted books, most recently deleted first
+// @Tags books
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} pageResp
+// @Failure 400 {object} gin.H
+// @Router /api/books/trash [get]
+func (h *BookHandler) Trash(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	q := db.Unscoped().Model(&book.Book{}).Where("deleted_at IS NOT NULL")
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil trash buku"})
+		return
+	}
+	items := []book.Book{}
+	if err := q.Scopes(withBookRelations).Order("deleted_at desc").Order("id asc").Limit(p.PageSize).Offset(p.Offset).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil trash buku"})
+		return
+	}
+	c.JSON(http.StatusOK, newPageResp(c, p, total, items))
+}
+
+// @Summary Restore book
+// @Description Restore a book from the trash
+// @Tags books
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID" format(uuid)
+// @Success 200 {object} map[string]book.Book
+// @Failure 400,404,409 {object} gin.H
+// @Router /api/books/{id}/restore [post]
+func (h *BookHandler) Restore(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	var item book.Book
+	if err := db.Unscoped().Scopes(withBookRelations).Where("deleted_at IS NOT NULL").First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "buku tidak ada di trash"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan buku"})
+		return
+	}
+
+	// kategori harus aktif agar buku tidak menunjuk ke kategori di trash
+	var activeCategory int64
+	if err := db.Model(&category.Category{}).Where("id = ?", item.CategoryID).Count(&activeCategory).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan buku"})
+		return
+	}
+	if activeCategory == 0 {
+		c.JSON(http.StatusConflict, gin.H{"message": "kategori buku masih di trash, pulihkan kategori terlebih dahulu"})
+		return
+	}
+
+	before, err := audit.Snapshot(item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan buku"})
+		return
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&book.Book{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Scopes(withBookRelations).First(&item, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionRestore, EntityType: entityBook, EntityID: id.String(), Before: before, After: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan buku"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// @Summary Purge book
+// @Description Permanently delete a book that is already in the trash
+// @Tags books
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID" format(uuid)
+// @Success 200 {object} gin.H
+// @Failure 400,404 {object} gin.H
+// @Router /api/books/{id}/purge [delete]
+func (h *BookHandler) Purge(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	var item book.Book
+	if err := db.Unscoped().Scopes(withBookRelations).Where("deleted_at IS NOT NULL").First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "buku tidak ada di trash"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus permanen buku"})
+		return
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&book.Book{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionPurge, EntityType: entityBook, EntityID: id.String(), Before: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus permanen buku"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "buku berhasil dihapus permanen"})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestSaveBookErr(t *testing.T) {
+	other := errors.New("koneksi putus")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nil", nil, nil},
+		{"duplicate isbn", gorm.ErrDuplicatedKey, errISBNTaken},
+		{"other error unchanged", other, other},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := saveBookErr(tt.err); got != tt.want {
+				t.Errorf("saveBookErr(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
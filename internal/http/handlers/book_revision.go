@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/book"
+	"github.com/qullDev/book_API/internal/domain/category"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revisionDiffResp perbedaan field antara dua revisi
+type revisionDiffResp struct {
+	From    int                     `json:"from"`
+	To      int                     `json:"to"`
+	Changes map[string]audit.Change `json:"changes"`
+}
+
+// saveBookRevision simpan snapshot b sebagai revisi berikutnya. Dipanggil setelah baris buku
+// di-update pada tx yang sama, sehingga row lock buku menjaga nomor revisi tetap berurutan.
+// before (opsional) disimpan dulu sebagai baseline jika buku belum punya revisi sama sekali.
+func saveBookRevision(tx *gorm.DB, before audit.JSON, b book.Book, action string) error {
+	var last int
+	if err := tx.Model(&book.Revision{}).Where("book_id = ?", b.ID).Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+		return err
+	}
+	if last == 0 && before != nil {
+		last++
+		if err := tx.Create(&book.Revision{BookID: b.ID, Number: last, Action: book.RevisionBaseline, Snapshot: before}).Error; err != nil {
+			return err
+		}
+	}
+	snap, err := audit.Snapshot(b)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&book.Revision{BookID: b.ID, Number: last + 1, Action: action, Snapshot: snap}).Error
+}
+
+// @Summary List book revisions
+// @Description Get paginated revision history of a book, newest first
+// @Tags books
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID" format(uuid)
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} pageResp
+// @Failure 400 {object} gin.H
+// @Router /api/books/{id}/revisions [get]
+func (h *BookHandler) Revisions(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	q := db.Model(&book.Revision{}).Where("book_id = ?", id)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil revisi buku"})
+		return
+	}
+	items := []book.Revision{}
+	if err := q.Preload("Creator").Order("number desc").Limit(p.PageSize).Offset(p.Offset).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil revisi buku"})
+		return
+	}
+	c.JSON(http.StatusOK, newPageResp(c, p, total, items))
+}
+
+// @Summary Get book revision
+// @Description Get a single revision snapshot of a book
+// @Tags books
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID" format(uuid)
+// @Param rev path int true "Revision number"
+// @Success 200 {object} map[string]book.Revision
+// @Failure 400,404 {object} gin.H
+// @Router /api/books/{id}/revisions/{rev} [get]
+func (h *BookHandler) RevisionDetail(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	num, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "nomor revisi tidak valid"})
+		return
+	}
+	var rev book.Revision
+	if err := db.Preload("Creator").First(&rev, "book_id = ? AND number = ?", id, num).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "revisi tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil revisi buku"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rev})
+}
+
+// @Summary Diff book revisions
+// @Description Compare two revisions of a book field by field. "to" defaults to the latest revision.
+// @Tags books
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID" format(uuid)
+// @Param from query int true "Base revision number"
+// @Param to query int false "Target revision number (default latest)"
+// @Success 200 {object} revisionDiffResp
+// @Failure 400,404 {object} gin.H
+// @Router /api/books/{id}/revisions/diff [get]
+func (h *BookHandler) RevisionDiff(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	from, err := queryInt(c, "from", 0)
+	if err != nil || from < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "from harus nomor revisi yang valid"})
+		return
+	}
+	to, err := queryInt(c, "to", 0)
+	if err != nil || to < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "to harus nomor revisi yang valid"})
+		return
+	}
+	if to == 0 {
+		if err := db.Model(&book.Revision{}).Where("book_id = ?", id).Select("COALESCE(MAX(number), 0)").Scan(&to).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil revisi buku"})
+			return
+		}
+	}
+
+	var revs []book.Revision
+	if err := db.Where("book_id = ? AND number IN ?", id, []int{from, to}).Find(&revs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil revisi buku"})
+		return
+	}
+	byNumber := map[int]book.Revision{}
+	for _, r := range revs {
+		byNumber[r.Number] = r
+	}
+	base, okFrom := byNumber[from]
+	target, okTo := byNumber[to]
+	if !okFrom || !okTo {
+		c.JSON(http.StatusNotFound, gin.H{"message": "revisi tidak ditemukan"})
+		return
+	}
+	changes, err := audit.Diff(base.Snapshot, target.Snapshot)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membandingkan revisi"})
+		return
+	}
+	c.JSON(http.StatusOK, revisionDiffResp{From: from, To: to, Changes: changes})
+}
+
+// @Summary Restore book revision
+// @Description Roll a book back to the content of a previous revision (fields and authors). The rollback is stored as a new revision.
+// @Tags books
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID" format(uuid)
+// @Param rev path int true "Revision number"
+// @Success 200 {object} map[string]book.Book
+// @Failure 400,404,409 {object} gin.H
+// @Router /api/books/{id}/revisions/{rev}/restore [post]
+func (h *BookHandler) RestoreRevision(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	num, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "nomor revisi tidak valid"})
+		return
+	}
+	var existing book.Book
+	if err := db.Scopes(withBookRelations).First(&existing, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "buku tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data buku"})
+		return
+	}
+	var rev book.Revision
+	if err := db.First(&rev, "book_id = ? AND number = ?", id, num).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "revisi tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil revisi buku"})
+		return
+	}
+	var snap book.Book
+	if err := json.Unmarshal(rev.Snapshot, &snap); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "snapshot revisi rusak"})
+		return
+	}
+	before, err := audit.Snapshot(existing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data buku"})
+		return
+	}
+
+	if taken, err := h.isbnTaken(snap.ISBN, existing.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan revisi"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"message": "isbn pada revisi sudah dipakai buku lain"})
+		return
+	}
+	var activeCategory int64
+	if err := db.Model(&category.Category{}).Where("id = ?", snap.CategoryID).Count(&activeCategory).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan revisi"})
+		return
+	}
+	if activeCategory == 0 {
+		c.JSON(http.StatusConflict, gin.H{"message": "kategori pada revisi sudah tidak tersedia"})
+		return
+	}
+
+	existing.Title = snap.Title
+	existing.ISBN = snap.ISBN
+	existing.CategoryID = snap.CategoryID
+	existing.Description = snap.Description
+	existing.ImageURL = snap.ImageURL
+	existing.ReleaseYear = snap.ReleaseYear
+	existing.Price = snap.Price
+	existing.TotalPage = snap.TotalPage
+	existing.Thickness = snap.Thickness
+
+	sort.SliceStable(snap.Authors, func(i, j int) bool { return snap.Authors[i].Position < snap.Authors[j].Position })
+	authorReqs := make([]bookAuthorReq, 0, len(snap.Authors))
+	for _, a := range snap.Authors {
+		authorReqs = append(authorReqs, bookAuthorReq{AuthorID: a.AuthorID, Role: a.Role})
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		authors, err := toBookAuthors(tx, authorReqs)
+		if err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(&existing).Error; err != nil {
+			return saveBookErr(err)
+		}
+		if err := replaceBookAuthors(tx, existing.ID, authors); err != nil {
+			return err
+		}
+		if err := tx.Scopes(withBookRelations).First(&existing, "id = ?", existing.ID).Error; err != nil {
+			return err
+		}
+		if err := saveBookRevision(tx, nil, existing, audit.ActionRollback); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionRollback, EntityType: entityBook, EntityID: existing.ID.String(), Before: before, After: existing})
+	})
+	if err != nil {
+		var ae bookAuthorError
+		if errors.As(err, &ae) {
+			c.JSON(http.StatusConflict, gin.H{"message": "author pada revisi sudah tidak tersedia"})
+			return
+		}
+		if errors.Is(err, errISBNTaken) {
+			c.JSON(http.StatusConflict, gin.H{"message": "isbn pada revisi sudah dipakai buku lain"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan revisi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": existing})
+}
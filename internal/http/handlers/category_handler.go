@@ -1,21 +1,27 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/config"
+	"github.com/qullDev/book_API/internal/domain/audit"
 	"github.com/qullDev/book_API/internal/domain/book"
 	"github.com/qullDev/book_API/internal/domain/category"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryHandler struct {
-	db *gorm.DB
+	db  *gorm.DB
+	cfg *config.Config
 }
 
-func NewCategoryHandler(db *gorm.DB) *CategoryHandler {
-	return &CategoryHandler{db: db}
+func NewCategoryHandler(db *gorm.DB, cfg *config.Config) *CategoryHandler {
+	return &CategoryHandler{db: db, cfg: cfg}
 }
 
 func (h *CategoryHandler) Register(rg *gin.RouterGroup) {
@@ -25,6 +31,9 @@ func (h *CategoryHandler) Register(rg *gin.RouterGroup) {
 	rg.PUT("/:id", h.Update)
 	rg.DELETE("/:id", h.Delete)
 	rg.GET("/:id/books", h.ListBooks)
+	rg.GET("/trash", h.Trash)
+	rg.POST("/:id/restore", h.Restore)
+	rg.DELETE("/:id/purge", h.Purge)
 }
 
 type createCategoryReq struct {
@@ -35,23 +44,70 @@ type updateCategoryReq struct {
 	Name string `json:"name" binding:"required,min=1,max=100"`
 }
 
+// categorySortFields whitelist kolom sort untuk cursor kategori
+var categorySortFields = map[string]sortField{
+	"name":       {Column: "name", Type: "text"},
+	"created_at": {Column: "created_at", Type: "timestamptz"},
+}
+
 // @Summary List all categories
-// @Description Get list of all categories
+// @Description Get list of all categories, or a page of them when cursor mode is used
 // @Tags categories
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Success 200 {object} map[string][]category.Category
+// @Param mode query string false "Set to cursor to start keyset pagination" Enums(cursor)
+// @Param cursor query string false "Opaque cursor from next_cursor of the previous page"
+// @Param page_size query int false "Page size in cursor mode (default 20, max 100)"
+// @Param sort query string false "Sort field in cursor mode" Enums(name, -name, created_at, -created_at)
+// @Success 200 {object} map[string][]category.Category "Cursor mode responds with cursorResp"
+// @Failure 400 {object} gin.H
 // @Router /api/categories [get]
 func (h *CategoryHandler) List(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	cp, cursorMode, err := parseCursorPage(c, h.cfg.CursorSecret, categorySortFields, "name")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if cursorMode {
+		h.listByCursor(c, cp)
+		return
+	}
+
 	var items []category.Category
-	if err := h.db.Order("name asc").Find(&items).Error; err != nil {
+	if err := db.Scopes(withUsers).Order("name asc").Find(&items).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data kategori"})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": items})
 }
 
+func (h *CategoryHandler) listByCursor(c *gin.Context, cp cursorPage) {
+	items := []category.Category{}
+	if err := cp.apply(h.db.WithContext(c.Request.Context()).Model(&category.Category{})).Scopes(withUsers).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data kategori"})
+		return
+	}
+	hasMore := len(items) > cp.PageSize
+	var lastValue string
+	var lastID uuid.UUID
+	if hasMore {
+		items = items[:cp.PageSize]
+		last := items[len(items)-1]
+		lastValue, lastID = last.Name, last.ID
+		if cp.Field.Column == "created_at" {
+			lastValue = last.CreatedAt.Format(time.RFC3339Nano)
+		}
+	}
+	resp, err := newCursorResp(c, h.cfg.CursorSecret, cp, items, hasMore, lastValue, lastID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat cursor"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // @Summary Create category
 // @Description Create a new category
 // @Tags categories
@@ -61,18 +117,34 @@ func (h *CategoryHandler) List(c *gin.Context) {
 // @Param category body createCategoryReq true "Category data" example({"name": "Fiction"})
 // @Success 201 {object} map[string]category.Category "example={'data':{'id':'550e8400-e29b-41d4-a716-446655440000','name':'Fiction','created_at':'2024-01-20T10:00:00Z'}}"
 // @Failure 400 {object} gin.H "example={'message':'payload tidak valid'}"
+// @Failure 409 {object} gin.H "example={'message':'nama kategori sudah dipakai'}"
 // @Router /api/categories [post]
 func (h *CategoryHandler) Create(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
 	var req createCategoryReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
 		return
 	}
 	item := category.Category{Name: req.Name}
-	if err := h.db.Create(&item).Error; err != nil {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionCreate, EntityType: entityCategory, EntityID: item.ID.String(), After: item})
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"message": "nama kategori sudah dipakai"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menambahkan kategori"})
 		return
 	}
+	if err := db.Scopes(withUsers).First(&item, "id = ?", item.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data kategori"})
+		return
+	}
 	c.JSON(http.StatusCreated, gin.H{"data": item})
 }
 
@@ -87,6 +159,7 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 // @Failure 400,404 {object} gin.H
 // @Router /api/categories/{id} [get]
 func (h *CategoryHandler) Detail(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -94,7 +167,7 @@ func (h *CategoryHandler) Detail(c *gin.Context) {
 		return
 	}
 	var item category.Category
-	if err := h.db.First(&item, "id = ?", id).Error; err != nil {
+	if err := db.Scopes(withUsers).First(&item, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"message": "kategori tidak ditemukan"})
 			return
@@ -114,9 +187,10 @@ func (h *CategoryHandler) Detail(c *gin.Context) {
 // @Param id path string true "Category ID" format(uuid)
 // @Param category body updateCategoryReq true "Updated category data"
 // @Success 200 {object} map[string]category.Category
-// @Failure 400,404 {object} gin.H
+// @Failure 400,404,409 {object} gin.H
 // @Router /api/categories/{id} [put]
 func (h *CategoryHandler) Update(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -129,7 +203,7 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 		return
 	}
 	var item category.Category
-	if err := h.db.First(&item, "id = ?", id).Error; err != nil {
+	if err := db.First(&item, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"message": "kategori tidak ditemukan"})
 			return
@@ -137,41 +211,301 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data kategori"})
 		return
 	}
+	before := item
 	item.Name = req.Name
-	if err := h.db.Save(&item).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionUpdate, EntityType: entityCategory, EntityID: item.ID.String(), Before: before, After: item})
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"message": "nama kategori sudah dipakai"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengupdate kategori"})
 		return
 	}
+	if err := db.Scopes(withUsers).First(&item, "id = ?", item.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data kategori"})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"data": item})
 }
 
+// Mode penghapusan kategori yang masih dipakai buku
+const (
+	deleteModeRestrict = "restrict" // tolak dengan 409 jika masih ada buku
+	deleteModeReassign = "reassign" // pindahkan buku ke kategori target_id
+	deleteModeCascade  = "cascade"  // trash kategori beserta bukunya
+)
+
 // @Summary Delete category
-// @Description Delete a category
+// @Description Move a category to the trash (soft delete). Books still using the category are handled according to mode.
 // @Tags categories
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "Category ID" format(uuid)
+// @Param mode query string false "What to do with books in the category (default restrict)" Enums(restrict, reassign, cascade)
+// @Param target_id query string false "Category that receives the books when mode=reassign" format(uuid)
 // @Success 200 {object} gin.H
 // @Failure 400,404 {object} gin.H
+// @Failure 409 {object} gin.H "example={'message':'kategori masih dipakai buku','book_count':3}"
 // @Router /api/categories/{id} [delete]
 func (h *CategoryHandler) Delete(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
 		return
 	}
-	res := h.db.Delete(&category.Category{}, "id = ?", id)
-	if res.Error != nil {
+
+	mode := c.DefaultQuery("mode", deleteModeRestrict)
+	var targetID uuid.UUID
+	switch mode {
+	case deleteModeRestrict, deleteModeCascade:
+	case deleteModeReassign:
+		targetID, err = uuid.Parse(c.Query("target_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "target_id tidak valid"})
+			return
+		}
+		if targetID == id {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "target_id tidak boleh sama dengan kategori yang dihapus"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"message": "mode harus restrict, reassign atau cascade"})
+		return
+	}
+
+	var item category.Category
+	if err := db.First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "kategori tidak tersedia"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus kategori"})
+		return
+	}
+
+	var bookCount int64
+	if err := db.Model(&book.Book{}).Where("category_id = ?", id).Count(&bookCount).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus kategori"})
 		return
 	}
-	if res.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"message": "kategori tidak tersedia"})
+	if mode == deleteModeRestrict && bookCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"message": "kategori masih dipakai buku", "book_count": bookCount})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "kategori berhasil dihapus"})
+	if mode == deleteModeReassign {
+		var target category.Category
+		if err := db.First(&target, "id = ?", targetID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "kategori target tidak ditemukan"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus kategori"})
+			return
+		}
+	}
+
+	// kategori dan bukunya di-trash dengan timestamp yang sama supaya bisa dipulihkan bersama
+	before := item
+	now := time.Now()
+	var affectedBooks int64
+	err = db.Transaction(func(tx *gorm.DB) error {
+		switch mode {
+		case deleteModeReassign:
+			// termasuk buku di trash, agar kategori lama tetap bisa di-purge
+			res := tx.Unscoped().Model(&book.Book{}).Where("category_id = ?", id).Update("category_id", targetID)
+			if res.Error != nil {
+				return res.Error
+			}
+			affectedBooks = res.RowsAffected
+		case deleteModeCascade:
+			// kunci kategori agar buku baru tidak bisa ditambahkan ke kategori ini sampai transaksi selesai;
+			// setiap buku yang ikut di-trash dicatat sendiri di audit
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category.Category{}, "id = ?", id).Error; err != nil {
+				return err
+			}
+			var books []book.Book
+			if err := tx.Scopes(withBookRelations).Where("category_id = ?", id).Find(&books).Error; err != nil {
+				return err
+			}
+			res := tx.Model(&book.Book{}).Where("category_id = ?", id).Update("deleted_at", now)
+			if res.Error != nil {
+				return res.Error
+			}
+			affectedBooks = res.RowsAffected
+			for _, b := range books {
+				if err := recordAudit(tx, c, auditEntry{Action: audit.ActionDelete, EntityType: entityBook, EntityID: b.ID.String(), Before: b}); err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Model(&item).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionDelete, EntityType: entityCategory, EntityID: id.String(), Before: before})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus kategori"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "kategori berhasil dihapus", "mode": mode, "affected_books": affectedBooks})
+}
+
+// @Summary List trashed categories
+// @Description Get list of soft-deleted categories, most recently deleted first
+// @Tags categories
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]category.Category
+// @Router /api/categories/trash [get]
+func (h *CategoryHandler) Trash(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var items []category.Category
+	if err := db.Unscoped().Scopes(withUsers).Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil trash kategori"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// @Summary Restore category
+// @Description Restore a category from the trash, optionally with the books that were trashed together with it
+// @Tags categories
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID" format(uuid)
+// @Param with_books query bool false "Also restore books deleted together with the category"
+// @Success 200 {object} gin.H
+// @Failure 400,404,409 {object} gin.H
+// @Router /api/categories/{id}/restore [post]
+func (h *CategoryHandler) Restore(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	withBooks := c.Query("with_books") == "true"
+
+	var item category.Category
+	if err := db.Unscoped().Scopes(withUsers).Where("deleted_at IS NOT NULL").First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "kategori tidak ada di trash"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan kategori"})
+		return
+	}
+
+	before, err := audit.Snapshot(item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan kategori"})
+		return
+	}
+	var restoredBooks int64
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if withBooks {
+			var books []book.Book
+			if err := tx.Unscoped().Scopes(withBookRelations).
+				Where("category_id = ? AND deleted_at = ?", id, item.DeletedAt.Time).
+				Find(&books).Error; err != nil {
+				return err
+			}
+			res := tx.Unscoped().Model(&book.Book{}).
+				Where("category_id = ? AND deleted_at = ?", id, item.DeletedAt.Time).
+				Update("deleted_at", nil)
+			if res.Error != nil {
+				return res.Error
+			}
+			restoredBooks = res.RowsAffected
+			for _, b := range books {
+				after := b
+				after.DeletedAt = gorm.DeletedAt{}
+				if err := recordAudit(tx, c, auditEntry{Action: audit.ActionRestore, EntityType: entityBook, EntityID: b.ID.String(), Before: b, After: after}); err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Unscoped().Model(&category.Category{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Scopes(withUsers).First(&item, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionRestore, EntityType: entityCategory, EntityID: id.String(), Before: before, After: item})
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"message": "nama kategori sudah dipakai kategori aktif lain"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memulihkan kategori"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item, "restored_books": restoredBooks})
+}
+
+// @Summary Purge category
+// @Description Permanently delete a trashed category together with its trashed books
+// @Tags categories
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID" format(uuid)
+// @Success 200 {object} gin.H
+// @Failure 400,404,409 {object} gin.H
+// @Router /api/categories/{id}/purge [delete]
+func (h *CategoryHandler) Purge(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	var item category.Category
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "kategori tidak ada di trash"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus permanen kategori"})
+		return
+	}
+
+	var activeBooks int64
+	if err := db.Model(&book.Book{}).Where("category_id = ?", id).Count(&activeBooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus permanen kategori"})
+		return
+	}
+	if activeBooks > 0 {
+		c.JSON(http.StatusConflict, gin.H{"message": "kategori masih dipakai buku yang aktif", "book_count": activeBooks})
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("category_id = ? AND deleted_at IS NOT NULL", id).Delete(&book.Book{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&category.Category{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionPurge, EntityType: entityCategory, EntityID: id.String(), Before: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus permanen kategori"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "kategori berhasil dihapus permanen"})
 }
 
 // @Summary List books in category
@@ -181,18 +515,34 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Category ID" format(uuid)
-// @Success 200 {object} map[string][]book.Book
+// @Param mode query string false "Set to cursor to start keyset pagination" Enums(cursor)
+// @Param cursor query string false "Opaque cursor from next_cursor of the previous page"
+// @Param page_size query int false "Page size in cursor mode (default 20, max 100)"
+// @Param sort query string false "Sort field in cursor mode, prefix with - for descending" Enums(title, -title, price, -price, release_year, -release_year, total_page, -total_page, created_at, -created_at)
+// @Success 200 {object} map[string][]book.Book "Cursor mode responds with cursorResp"
 // @Failure 400 {object} gin.H
 // @Router /api/categories/{id}/books [get]
 func (h *CategoryHandler) ListBooks(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
 		return
 	}
+
+	cp, cursorMode, err := parseCursorPage(c, h.cfg.CursorSecret, bookSortFields, "title")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if cursorMode {
+		listBooksByCursor(c, h.cfg, db.Model(&book.Book{}).Where("category_id = ?", id), cp)
+		return
+	}
+
 	var books []book.Book
-	if err := h.db.Where("category_id = ?", id).Find(&books).Error; err != nil {
+	if err := db.Scopes(withBookRelations).Where("category_id = ?", id).Find(&books).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil buku pada kategori"})
 		return
 	}
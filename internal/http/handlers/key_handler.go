@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	appauth "github.com/qullDev/book_API/internal/pkg/auth"
+)
+
+// KeyHandler publikasi public key penanda tangan token
+type KeyHandler struct {
+	keys *appauth.KeyRing
+}
+
+func NewKeyHandler(keys *appauth.KeyRing) *KeyHandler {
+	return &KeyHandler{keys: keys}
+}
+
+type jwksResp struct {
+	Keys []appauth.JWK `json:"keys"`
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens, selected by the kid header. Includes the next key before it is used and previous keys while their tokens can still be valid. Empty when tokens are signed with HS256.
+// @Tags keys
+// @Produce json
+// @Success 200 {object} jwksResp
+// @Router /.well-known/jwks.json [get]
+func (h *KeyHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwksResp{Keys: h.keys.JWKS()})
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/oauth"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"github.com/qullDev/book_API/internal/pkg/password"
+)
+
+// authorizePage halaman login & persetujuan untuk client pihak ketiga. Tidak memakai cookie:
+// user login ulang di setiap authorization request, sehingga tidak ada sesi browser yang bisa di-CSRF.
+var authorizePage = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Izinkan akses - Book API</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:26rem;margin:3rem auto;padding:0 1rem;color:#222}
+label{display:block;margin-top:.8rem}
+input{width:100%;padding:.45rem;box-sizing:border-box}
+.error{color:#b00020}
+.actions{margin-top:1.2rem;display:flex;gap:.5rem}
+</style>
+</head>
+<body>
+{{if .Client}}
+<h1>{{.Client}}</h1>
+<p>meminta akses ke akun Book API Anda dengan izin:</p>
+<ul>{{range .Scopes}}<li><code>{{.}}</code></li>{{end}}</ul>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth/authorize">
+<input type="hidden" name="response_type" value="{{.Req.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.Req.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Req.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Req.Scope}}">
+<input type="hidden" name="state" value="{{.Req.State}}">
+<input type="hidden" name="code_challenge" value="{{.Req.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Req.CodeChallengeMethod}}">
+<label>Username <input name="username" value="{{.Username}}" autocomplete="username" autofocus></label>
+<label>Password <input type="password" name="password" autocomplete="current-password"></label>
+<label>Kode MFA (jika aktif) <input name="mfa_code" autocomplete="one-time-code" inputmode="numeric"></label>
+<div class="actions">
+<button type="submit" name="decision" value="approve">Izinkan</button>
+<button type="submit" name="decision" value="deny">Tolak</button>
+</div>
+</form>
+<p>Anda akan diarahkan ke <code>{{.Redirect}}</code>.</p>
+{{else}}
+<h1>Permintaan tidak valid</h1>
+<p class="error">{{.Error}}</p>
+{{end}}
+</body>
+</html>
+`))
+
+type authorizePageData struct {
+	Client   string // kosong = halaman error tanpa form
+	Scopes   []string
+	Redirect string // host redirect_uri agar user tahu tujuan code
+	Req      authorizeReq
+	Username string
+	Error    string
+}
+
+// @Summary OAuth authorize page
+// @Description Browser entry point of the authorization_code grant (RFC 6749 section 4.1). Shows an HTML login and consent page for the client. Invalid client_id or redirect_uri render an error page; other errors redirect to the client with error and state. PKCE with S256 is required.
+// @Tags oauth
+// @Produce html
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string false "Requested scope, space-separated"
+// @Param state query string false "Opaque value returned to the client"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 200 {string} string "HTML page"
+// @Failure 400 {string} string "HTML error page"
+// @Router /oauth/authorize [get]
+func (h *OAuthHandler) AuthorizePage(c *gin.Context) {
+	client, req, ok := h.authorizeRequest(c)
+	if !ok {
+		return
+	}
+	h.renderAuthorize(c, http.StatusOK, authorizePageData{Req: req}, client)
+}
+
+// @Summary Submit OAuth authorize page
+// @Description Form post of the page from GET /oauth/authorize. The user logs in with username, password and, when MFA is on, a TOTP or recovery code. Failures count towards login throttling. Approve redirects to the redirect_uri with code and state. Deny redirects with error=access_denied.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param decision formData string true "approve or deny"
+// @Param username formData string false "Username"
+// @Param password formData string false "Password"
+// @Param mfa_code formData string false "TOTP or recovery code"
+// @Success 303 {string} string "Redirect to the client"
+// @Failure 400,401,403,429 {string} string "HTML page"
+// @Router /oauth/authorize [post]
+func (h *OAuthHandler) AuthorizeSubmit(c *gin.Context) {
+	client, req, ok := h.authorizeRequest(c)
+	if !ok {
+		return
+	}
+	if c.PostForm("decision") != "approve" {
+		redirectAuthorizeError(c, req, "access_denied", "user menolak permintaan akses")
+		return
+	}
+	ctx := c.Request.Context()
+	db := h.db.WithContext(ctx)
+	username := strings.TrimSpace(c.PostForm("username"))
+	page := authorizePageData{Req: req, Username: username}
+
+	attempt, wait, err := h.guard.Reserve(ctx, username, c.ClientIP())
+	if err != nil {
+		page.Error = "gagal memproses login"
+		h.renderAuthorize(c, http.StatusInternalServerError, page, client)
+		return
+	}
+	if wait > 0 {
+		h.renderThrottled(c, page, client, wait)
+		return
+	}
+	defer releaseAttempt(ctx, attempt)
+
+	var u user.User
+	if err := db.Where("username = ?", username).Limit(1).Find(&u).Error; err != nil {
+		page.Error = "gagal memproses login"
+		h.renderAuthorize(c, http.StatusInternalServerError, page, client)
+		return
+	}
+	reason := ""
+	switch ok, _, err := h.passwords.Verify(u.Password, c.PostForm("password")); {
+	case u.ID == uuid.Nil:
+		reason = "user_not_found"
+	case err != nil && err != password.ErrUnknownFormat:
+		log.Println("verifikasi password gagal:", err)
+		page.Error = "gagal memproses login"
+		h.renderAuthorize(c, http.StatusInternalServerError, page, client)
+		return
+	case !ok:
+		reason = "invalid_password"
+	}
+	if reason == "" && u.Disabled {
+		page.Error = "akun dinonaktifkan"
+		h.renderAuthorize(c, http.StatusForbidden, page, client)
+		return
+	}
+	if reason == "" && u.MFAEnabled {
+		code := c.PostForm("mfa_code")
+		if strings.TrimSpace(code) == "" {
+			page.Error = "akun ini memakai MFA: masukkan password lagi beserta kode dari authenticator atau recovery code"
+			h.renderAuthorize(c, http.StatusUnauthorized, page, client)
+			return
+		}
+		ok, recovery, err := verifyMFACode(db, h.box, u, code)
+		if err != nil {
+			page.Error = "gagal memproses login"
+			h.renderAuthorize(c, http.StatusInternalServerError, page, client)
+			return
+		}
+		if !ok {
+			reason = "invalid_mfa_code"
+		} else if recovery {
+			h.audit(c, auditEntry{Action: audit.ActionMFARecovery, EntityType: entityUser, EntityID: u.ID.String(), ActorID: u.ID})
+		}
+	}
+	if reason != "" {
+		e := auditEntry{Action: audit.ActionLoginFailed, EntityType: entityUser, After: gin.H{"username": username, "reason": reason, "client_id": client.ID.String()}}
+		var failed *user.User
+		if u.ID != uuid.Nil {
+			e.EntityID, failed = u.ID.String(), &u
+		}
+		h.audit(c, e)
+		if res := recordLoginFailure(c, h.db, attempt, username, failed); res.UserLocked || res.IPLocked {
+			h.renderThrottled(c, page, client, res.RetryAfter)
+			return
+		}
+		page.Error = "username, password atau kode MFA salah"
+		h.renderAuthorize(c, http.StatusUnauthorized, page, client)
+		return
+	}
+	if err := attempt.Succeed(ctx); err != nil {
+		log.Println("reset percobaan login gagal:", err)
+	}
+
+	// akun yang wajib ganti password atau mendaftarkan MFA harus menyelesaikannya lewat login biasa
+	required, err := mfaRequired(db, u.Role)
+	if err != nil {
+		page.Error = "gagal memproses login"
+		h.renderAuthorize(c, http.StatusInternalServerError, page, client)
+		return
+	}
+	if u.PasswordResetRequired || (required && !u.MFAEnabled) {
+		page.Error = "akun Anda perlu mengganti password atau mengaktifkan MFA terlebih dulu; login ke aplikasi utama lalu coba lagi"
+		h.renderAuthorize(c, http.StatusForbidden, page, client)
+		return
+	}
+
+	scope, ok := authorizedScope(client, u, req.Scope)
+	if !ok {
+		redirectAuthorizeError(c, req, "invalid_scope", "scope di luar scope client atau role user")
+		return
+	}
+	target, _, err := h.issueCode(c, client, u, req, scope)
+	if err != nil {
+		redirectAuthorizeError(c, req, "server_error", "gagal membuat authorization code")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, target)
+}
+
+// authorizeRequest baca parameter authorize dari query (GET) atau form (POST). Selama client_id dan
+// redirect_uri belum terbukti valid, error ditampilkan sebagai halaman dan tidak di-redirect
+// (RFC 6749 bagian 4.1.2.1). Response sudah ditulis jika ok false.
+func (h *OAuthHandler) authorizeRequest(c *gin.Context) (*oauth.Client, authorizeReq, bool) {
+	var req authorizeReq
+	// field tetap terisi walau validasi binding gagal; error-nya dilaporkan setelah redirect_uri dicek
+	bindErr := c.ShouldBind(&req)
+	client, err := h.findClient(h.db.WithContext(c.Request.Context()), req.ClientID)
+	if err != nil {
+		h.renderAuthorize(c, http.StatusInternalServerError, authorizePageData{Error: "gagal mengambil data client"}, nil)
+		return nil, req, false
+	}
+	if client == nil || !client.AllowsGrant(oauth.GrantAuthorizationCode) || !client.RedirectURIs.Has(req.RedirectURI) {
+		h.renderAuthorize(c, http.StatusBadRequest, authorizePageData{Error: "client tidak dikenal atau redirect_uri tidak terdaftar"}, nil)
+		return nil, req, false
+	}
+	if bindErr != nil {
+		redirectAuthorizeError(c, req, "invalid_request", "response_type=code dan PKCE (code_challenge, code_challenge_method=S256) wajib")
+		return nil, req, false
+	}
+	return client, req, true
+}
+
+// renderAuthorize tulis halaman authorize; client nil = halaman error tanpa form
+func (h *OAuthHandler) renderAuthorize(c *gin.Context, status int, data authorizePageData, client *oauth.Client) {
+	if client != nil {
+		data.Client = client.Name
+		data.Scopes = client.Scopes
+		if data.Req.Scope != "" {
+			data.Scopes = user.ParseScopes(data.Req.Scope)
+		}
+		if target, err := url.Parse(data.Req.RedirectURI); err == nil {
+			data.Redirect = target.Host
+		}
+	}
+	// halaman berisi form password: tidak boleh di-frame (clickjacking) atau di-cache
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := authorizePage.Execute(c.Writer, data); err != nil {
+		log.Println("render halaman authorize gagal:", err)
+	}
+}
+
+// renderThrottled halaman 429 dengan header Retry-After seperti login API
+func (h *OAuthHandler) renderThrottled(c *gin.Context, data authorizePageData, client *oauth.Client, wait time.Duration) {
+	secs := int64(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.FormatInt(secs, 10))
+	data.Error = fmt.Sprintf("terlalu banyak percobaan login, coba lagi dalam %d detik", secs)
+	h.renderAuthorize(c, http.StatusTooManyRequests, data, client)
+}
+
+// redirectAuthorizeError kirim error ke client lewat redirect_uri yang sudah divalidasi (RFC 6749 bagian 4.1.2.1)
+func redirectAuthorizeError(c *gin.Context, req authorizeReq, code, desc string) {
+	target, _ := url.Parse(req.RedirectURI)
+	q := target.Query()
+	q.Set("error", code)
+	q.Set("error_description", desc)
+	if req.State != "" {
+		q.Set("state", req.State)
+	}
+	target.RawQuery = q.Encode()
+	c.Redirect(http.StatusSeeOther, target.String())
+}
+
+// audit catat event auth; kegagalan menulis audit tidak menggagalkan request
+func (h *OAuthHandler) audit(c *gin.Context, e auditEntry) {
+	if err := recordAudit(h.db.WithContext(c.Request.Context()), c, e); err != nil {
+		log.Println("audit auth event gagal:", err)
+	}
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/oauth"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"gorm.io/gorm"
+)
+
+// RegisterClients daftarkan route pengelolaan client OAuth pada group /api/admin/oauth-clients
+func (h *OAuthHandler) RegisterClients(rg *gin.RouterGroup) {
+	rg.GET("", h.Clients)
+	rg.POST("", h.CreateClient)
+	rg.DELETE("/:id", h.DeleteClient)
+	rg.POST("/:id/enable", h.EnableClient)
+}
+
+type createClientReq struct {
+	Name         string   `json:"name" binding:"required,min=1,max=100"`
+	Public       bool     `json:"public"` // true untuk SPA/aplikasi mobile yang tidak bisa menyimpan secret
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1,dive,oneof=client_credentials authorization_code refresh_token"`
+	Scopes       []string `json:"scopes" binding:"required,min=1"`
+}
+
+// createClientResp secret hanya dikirim sekali saat client dibuat
+type createClientResp struct {
+	ClientSecret string       `json:"client_secret,omitempty"`
+	Data         oauth.Client `json:"data"`
+}
+
+// @Summary List OAuth clients
+// @Description Get registered OAuth clients, newest first. Secrets are never returned.
+// @Tags oauth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string][]oauth.Client
+// @Failure 401,403 {object} gin.H
+// @Router /api/admin/oauth-clients [get]
+func (h *OAuthHandler) Clients(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	items := []oauth.Client{}
+	if err := db.Order("created_at desc").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil daftar client"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// @Summary Register OAuth client
+// @Description Register a client for the OAuth2 token endpoint. Confidential clients get a client_secret, returned only in this response. Public clients (SPA, mobile) have no secret and may only use authorization_code with PKCE and refresh_token. Redirect URIs must be https, or http on a loopback host. The admin who registers the client becomes its owner: client_credentials tokens act as that admin and are limited by the admin's role.
+// @Tags oauth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param client body createClientReq true "Client data" example({"name": "Partner import", "grant_types": ["client_credentials"], "scopes": ["books:read", "books:write"]})
+// @Success 201 {object} createClientResp
+// @Failure 400,401,403 {object} gin.H
+// @Router /api/admin/oauth-clients [post]
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req createClientReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	grants := oauth.List(req.GrantTypes)
+	if req.Public && grants.Has(oauth.GrantClientCredentials) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "client public tidak boleh memakai client_credentials"})
+		return
+	}
+	if grants.Has(oauth.GrantRefreshToken) && !grants.Has(oauth.GrantAuthorizationCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "refresh_token hanya bisa dipakai bersama authorization_code"})
+		return
+	}
+	if grants.Has(oauth.GrantAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "authorization_code membutuhkan minimal satu redirect_uri"})
+		return
+	}
+	for _, raw := range req.RedirectURIs {
+		if !validRedirectURI(raw) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "redirect_uri harus URL https tanpa fragment (http hanya untuk loopback): " + raw})
+			return
+		}
+	}
+
+	// pemilik selalu admin yang mendaftarkan, sehingga audit dari token client_credentials
+	// tercatat atas nama orang yang memang membuat client
+	var owner user.User
+	if err := db.First(&owner, "id = ?", c.GetString("userID")).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	// token client_credentials bertindak sebagai pemilik sehingga scope-nya dibatasi role pemilik;
+	// untuk authorization_code batasnya role user yang memberi izin saat authorize
+	scopes := user.ParseScopes(strings.Join(req.Scopes, " "))
+	limit := user.AllScopes()
+	if grants.Has(oauth.GrantClientCredentials) {
+		limit = user.RoleScopes(owner.Role)
+	}
+	// client tidak boleh mendapat scope melebihi token yang dipakai untuk mendaftarkannya
+	if v, ok := c.Get("scopes"); ok {
+		limit = limit.Intersect(user.Scopes(v.([]string)))
+	}
+	if !limit.Contains(scopes) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "scope tidak dikenal atau di luar hak akses pemilik client"})
+		return
+	}
+
+	item := oauth.Client{
+		Name:         req.Name,
+		Public:       req.Public,
+		RedirectURIs: oauth.List(req.RedirectURIs),
+		GrantTypes:   grants,
+		Scopes:       scopes,
+		UserID:       owner.ID,
+	}
+	var secret string
+	var err error
+	if !req.Public {
+		if secret, item.SecretHash, err = oauth.NewClientSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat client secret"})
+			return
+		}
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionCreate, EntityType: entityClient, EntityID: item.ID.String(), After: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mendaftarkan client"})
+		return
+	}
+	c.JSON(http.StatusCreated, createClientResp{ClientSecret: secret, Data: item})
+}
+
+// @Summary Enable OAuth client
+// @Description Re-enable a client that was disabled because its owner's password was reset or sessions were revoked. Confidential clients get a new client_secret, returned only in this response; the old secret stays invalid.
+// @Tags oauth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Client ID" format(uuid)
+// @Success 200 {object} createClientResp
+// @Failure 400,401,403,404 {object} gin.H
+// @Router /api/admin/oauth-clients/{id}/enable [post]
+func (h *OAuthHandler) EnableClient(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	var item oauth.Client
+	if err := db.First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "client tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengaktifkan client"})
+		return
+	}
+	before := item
+	var secret string
+	if !item.Public {
+		// secret lama mungkin ikut bocor bersama kredensial pemilik, jadi selalu diganti
+		if secret, item.SecretHash, err = oauth.NewClientSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat client secret"})
+			return
+		}
+	}
+	item.Disabled = false
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&oauth.Client{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"disabled":    false,
+			"secret_hash": item.SecretHash,
+		}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionUpdate, EntityType: entityClient, EntityID: id.String(), Before: before, After: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengaktifkan client"})
+		return
+	}
+	c.JSON(http.StatusOK, createClientResp{ClientSecret: secret, Data: item})
+}
+
+// @Summary Delete OAuth client
+// @Description Delete a client. Its refresh tokens and client_credentials access tokens stop working immediately; access tokens from authorization_code expire on their own.
+// @Tags oauth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Client ID" format(uuid)
+// @Success 200 {object} gin.H
+// @Failure 400,401,403,404 {object} gin.H
+// @Router /api/admin/oauth-clients/{id} [delete]
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	var item oauth.Client
+	if err := db.First(&item, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "client tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus client"})
+		return
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&oauth.Client{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionDelete, EntityType: entityClient, EntityID: id.String(), Before: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus client"})
+		return
+	}
+	// refresh token client tidak bisa dipakai lagi karena autentikasi client gagal. Client sudah terhapus,
+	// jadi kegagalan mencabut access token hanya dicatat; token itu kedaluwarsa sendiri dalam ACCESS_TOKEN_TTL
+	if err := h.ts.DenySessionAccessTokens(c.Request.Context(), item.UserID.String(), clientSession(item.ID)); err != nil {
+		log.Printf("cabut access token client %s gagal: %v", item.ID, err)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "client berhasil dihapus"})
+}
+
+// disableOwnedClients nonaktifkan client client_credentials milik user di dalam tx. Token client itu
+// bertindak sebagai pemilik, jadi ikut dihentikan saat password pemilik direset atau sesinya dicabut.
+// actorID uuid.Nil = user dari context request.
+func disableOwnedClients(tx *gorm.DB, c *gin.Context, userID, actorID uuid.UUID) error {
+	var clients []oauth.Client
+	if err := tx.Where("user_id = ? AND disabled = ?", userID, false).Find(&clients).Error; err != nil {
+		return err
+	}
+	for _, cl := range clients {
+		if !cl.AllowsGrant(oauth.GrantClientCredentials) {
+			continue
+		}
+		if err := tx.Model(&oauth.Client{}).Where("id = ?", cl.ID).Update("disabled", true).Error; err != nil {
+			return err
+		}
+		after := cl
+		after.Disabled = true
+		if err := recordAudit(tx, c, auditEntry{Action: audit.ActionUpdate, EntityType: entityClient, EntityID: cl.ID.String(), ActorID: actorID, Before: cl, After: after}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validRedirectURI hanya https, atau http ke alamat loopback untuk aplikasi native (RFC 8252 bagian 7.3).
+// Skema lain (javascript:, data:, custom scheme) ditolak agar code tidak bisa dikirim ke tujuan sembarang.
+func validRedirectURI(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.Fragment != "" || u.User != nil || strings.ContainsAny(raw, " ") {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	}
+	return false
+}
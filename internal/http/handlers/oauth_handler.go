@@ -0,0 +1,486 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/config"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/oauth"
+	"github.com/qullDev/book_API/internal/domain/user"
+	appauth "github.com/qullDev/book_API/internal/pkg/auth"
+	"github.com/qullDev/book_API/internal/pkg/password"
+	"github.com/qullDev/book_API/internal/pkg/secret"
+	"gorm.io/gorm"
+)
+
+// OAuthHandler authorization server OAuth2 (RFC 6749): authorization code dengan PKCE,
+// client credentials dan refresh token, memakai generator token dan TokenStore yang sama dengan login biasa
+type OAuthHandler struct {
+	db        *gorm.DB
+	ts        *appauth.TokenStore
+	cfg       *config.Config
+	keys      *appauth.KeyRing
+	passwords *password.Manager   // login di halaman authorize
+	guard     *appauth.LoginGuard // throttle login di halaman authorize
+	box       *secret.Box         // dekripsi secret MFA di halaman authorize
+}
+
+func NewOAuthHandler(db *gorm.DB, ts *appauth.TokenStore, cfg *config.Config, keys *appauth.KeyRing, passwords *password.Manager, guard *appauth.LoginGuard, box *secret.Box) *OAuthHandler {
+	return &OAuthHandler{db: db, ts: ts, cfg: cfg, keys: keys, passwords: passwords, guard: guard, box: box}
+}
+
+// authorizeReq parameter authorization request; JSON untuk POST /api/oauth/authorize,
+// query/form untuk halaman /oauth/authorize
+type authorizeReq struct {
+	ResponseType        string `json:"response_type" form:"response_type" binding:"required,eq=code"`
+	ClientID            string `json:"client_id" form:"client_id" binding:"required"`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri" binding:"required"`
+	Scope               string `json:"scope" form:"scope"` // opsional, default semua scope client yang dimiliki role user
+	State               string `json:"state" form:"state"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge" binding:"required,min=43,max=128"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method" binding:"required,eq=S256"`
+}
+
+type authorizeResp struct {
+	RedirectTo string `json:"redirect_to"` // redirect_uri lengkap dengan code dan state
+	Code       string `json:"code"`
+	State      string `json:"state,omitempty"`
+	ExpiresIn  int64  `json:"expires_in"`
+}
+
+// oauthTokenResp response endpoint token sesuai RFC 6749 bagian 5.1
+type oauthTokenResp struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+// oauthErrorResp response error endpoint token sesuai RFC 6749 bagian 5.2
+type oauthErrorResp struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// @Summary Authorize OAuth client
+// @Description Issue an authorization code for the logged-in user to a registered client (authorization_code grant). For first-party frontends that already hold the user's access token and show their own consent screen; third-party clients use the browser page GET /oauth/authorize. PKCE with S256 is required. The redirect_uri must match a registered URI exactly.
+// @Tags oauth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param authorizeRequest body authorizeReq true "Authorization request" example({"response_type": "code", "client_id": "6f1c...", "redirect_uri": "https://app.example.com/callback", "scope": "books:read", "state": "xyz", "code_challenge": "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", "code_challenge_method": "S256"})
+// @Success 200 {object} authorizeResp
+// @Failure 400,401,403 {object} gin.H
+// @Router /api/oauth/authorize [post]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req authorizeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	client, err := h.findClient(db, req.ClientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data client"})
+		return
+	}
+	if client == nil || !client.AllowsGrant(oauth.GrantAuthorizationCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "client tidak dikenal atau tidak boleh memakai authorization_code"})
+		return
+	}
+	// jangan pernah redirect ke URI yang tidak terdaftar
+	if !client.RedirectURIs.Has(req.RedirectURI) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "redirect_uri tidak terdaftar untuk client ini"})
+		return
+	}
+	var u user.User
+	if err := db.First(&u, "id = ?", c.GetString("userID")).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	scope, ok := authorizedScope(client, u, req.Scope)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "scope di luar scope client atau role Anda"})
+		return
+	}
+	target, code, err := h.issueCode(c, client, u, req, scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat authorization code"})
+		return
+	}
+	c.JSON(http.StatusOK, authorizeResp{RedirectTo: target, Code: code, State: req.State, ExpiresIn: int64(h.cfg.OAuthCodeTTL.Seconds())})
+}
+
+// authorizedScope scope yang diminta harus bagian dari scope client dan role user; kosong berarti semua yang boleh
+func authorizedScope(client *oauth.Client, u user.User, raw string) (user.Scopes, bool) {
+	allowed := client.Scopes.Intersect(user.RoleScopes(u.Role))
+	scope := allowed
+	if raw != "" {
+		scope = user.ParseScopes(raw)
+		if !allowed.Contains(scope) {
+			return nil, false
+		}
+	}
+	return scope, len(scope) > 0
+}
+
+// issueCode simpan authorization code sekali pakai lalu kembalikan redirect_uri lengkap dengan code dan state
+func (h *OAuthHandler) issueCode(c *gin.Context, client *oauth.Client, u user.User, req authorizeReq, scope user.Scopes) (target, code string, err error) {
+	if code, err = randomToken(); err != nil {
+		return "", "", err
+	}
+	err = h.ts.SaveAuthCode(c.Request.Context(), code, appauth.AuthCode{
+		ClientID:      client.ID.String(),
+		UserID:        u.ID.String(),
+		RedirectURI:   req.RedirectURI,
+		Scope:         scope.String(),
+		CodeChallenge: req.CodeChallenge,
+	}, h.cfg.OAuthCodeTTL)
+	if err != nil {
+		return "", "", err
+	}
+	if err := recordAudit(h.db.WithContext(c.Request.Context()), c, auditEntry{Action: audit.ActionAuthorize, EntityType: entityClient, EntityID: client.ID.String(), ActorID: u.ID, After: gin.H{"scope": scope.String()}}); err != nil {
+		log.Println("audit auth event gagal:", err)
+	}
+
+	redirect, _ := url.Parse(req.RedirectURI)
+	q := redirect.Query()
+	q.Set("code", code)
+	if req.State != "" {
+		q.Set("state", req.State)
+	}
+	redirect.RawQuery = q.Encode()
+	return redirect.String(), code, nil
+}
+
+// @Summary OAuth2 token endpoint
+// @Description Exchange a grant for tokens (RFC 6749). grant_type client_credentials (confidential clients), authorization_code (with redirect_uri and PKCE code_verifier) or refresh_token (rotated like /api/users/refresh). Clients authenticate with HTTP Basic or client_id/client_secret form fields; public clients send client_id only. Errors use the standard error/error_description body.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "Grant type" Enums(client_credentials, authorization_code, refresh_token)
+// @Param client_id formData string false "Client ID (when not using HTTP Basic)"
+// @Param client_secret formData string false "Client secret (confidential clients, when not using HTTP Basic)"
+// @Param scope formData string false "Requested scope, space-separated"
+// @Param code formData string false "Authorization code (authorization_code)"
+// @Param redirect_uri formData string false "Redirect URI used to obtain the code (authorization_code)"
+// @Param code_verifier formData string false "PKCE code verifier (authorization_code)"
+// @Param refresh_token formData string false "Refresh token (refresh_token)"
+// @Success 200 {object} oauthTokenResp
+// @Failure 400,401 {object} oauthErrorResp
+// @Router /api/oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	// response token tidak boleh di-cache (RFC 6749 bagian 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	client, ok := h.authenticateClient(c)
+	if !ok {
+		return
+	}
+	grant := c.PostForm("grant_type")
+	switch grant {
+	case oauth.GrantClientCredentials, oauth.GrantAuthorizationCode, oauth.GrantRefreshToken:
+	default:
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "grant_type tidak didukung")
+		return
+	}
+	if !client.AllowsGrant(grant) || (grant == oauth.GrantClientCredentials && client.Public) {
+		oauthError(c, http.StatusBadRequest, "unauthorized_client", "client tidak boleh memakai grant_type ini")
+		return
+	}
+	switch grant {
+	case oauth.GrantClientCredentials:
+		h.clientCredentials(c, client)
+	case oauth.GrantAuthorizationCode:
+		h.authorizationCode(c, client)
+	case oauth.GrantRefreshToken:
+		h.refreshToken(c, client)
+	}
+}
+
+// clientCredentials token atas nama pemilik client, tanpa refresh token (RFC 6749 bagian 4.4.3)
+func (h *OAuthHandler) clientCredentials(c *gin.Context, client *oauth.Client) {
+	db := h.db.WithContext(c.Request.Context())
+	var u user.User
+	if err := db.Where("id = ?", client.UserID).Limit(1).Find(&u).Error; err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "gagal mengambil pemilik client")
+		return
+	}
+	if u.ID != client.UserID || u.Disabled {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "pemilik client tidak aktif")
+		return
+	}
+	scope, ok := grantScope(c, c.PostForm("scope"), client.Scopes.Intersect(user.RoleScopes(u.Role)))
+	if !ok {
+		return
+	}
+	// access token client dicatat dengan label sesi client agar bisa dicabut saat client dihapus
+	h.issue(c, client, u, clientSession(client.ID), scope, nil, oauth.GrantClientCredentials)
+}
+
+// authorizationCode tukar code dari Authorize; code hanya berlaku sekali dan terikat ke client, redirect_uri dan PKCE
+func (h *OAuthHandler) authorizationCode(c *gin.Context, client *oauth.Client) {
+	ctx := c.Request.Context()
+	code, verifier := c.PostForm("code"), c.PostForm("code_verifier")
+	if code == "" || verifier == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "code dan code_verifier wajib diisi")
+		return
+	}
+	data, found, err := h.ts.ConsumeAuthCode(ctx, code)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "gagal memverifikasi authorization code")
+		return
+	}
+	if !found || data.ClientID != client.ID.String() || data.RedirectURI != c.PostForm("redirect_uri") || !oauth.VerifyPKCE(verifier, data.CodeChallenge) {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "authorization code tidak valid atau kedaluwarsa")
+		return
+	}
+	u, ok := h.activeUser(c, data.UserID)
+	if !ok {
+		return
+	}
+	// role atau scope client bisa berubah sejak code dibuat
+	scope := user.ParseScopes(data.Scope).Intersect(client.Scopes).Intersect(user.RoleScopes(u.Role))
+	if len(scope) == 0 {
+		oauthError(c, http.StatusBadRequest, "invalid_scope", "scope tidak lagi diizinkan")
+		return
+	}
+
+	// setiap authorization code memulai family RT (sesi) baru
+	family := uuid.New().String()
+	var refresh *appauth.RefreshGrant
+	if client.AllowsGrant(oauth.GrantRefreshToken) {
+		refresh = &appauth.RefreshGrant{Family: family, Scope: scope.String(), ClientID: client.ID.String()}
+	}
+	h.issue(c, client, u, family, scope, refresh, oauth.GrantAuthorizationCode)
+}
+
+// refreshToken rotasi RT milik client; deteksi reuse sama dengan /api/users/refresh
+func (h *OAuthHandler) refreshToken(c *gin.Context, client *oauth.Client) {
+	ctx := c.Request.Context()
+	claims, err := appauth.ParseRefreshToken(h.cfg, h.keys, c.PostForm("refresh_token"))
+	if err != nil || claims.ClientID != client.ID.String() || claims.Family == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "refresh token tidak valid")
+		return
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "refresh token tidak valid")
+		return
+	}
+	// scope diperiksa sebelum rotasi agar permintaan yang salah tidak menghabiskan RT
+	limit := user.ParseScopes(claims.Scope)
+	requested := user.ParseScopes(c.PostForm("scope"))
+	if !limit.Contains(requested) {
+		oauthError(c, http.StatusBadRequest, "invalid_scope", "scope melebihi scope refresh token")
+		return
+	}
+	if len(requested) == 0 {
+		requested = limit
+	}
+
+	u, ok := h.activeUser(c, claims.UserID)
+	if !ok {
+		return
+	}
+	scope := requested.Intersect(client.Scopes).Intersect(user.RoleScopes(u.Role))
+	if len(scope) == 0 {
+		oauthError(c, http.StatusBadRequest, "invalid_scope", "scope tidak lagi diizinkan")
+		return
+	}
+
+	// semua token dibuat sebelum rotasi: rotasi langkah terakhir yang bisa gagal,
+	// sehingga RT lama tidak hangus tanpa client menerima penggantinya
+	grant := appauth.RefreshGrant{Family: claims.Family, Scope: claims.Scope, ClientID: claims.ClientID}
+	at, ok := h.accessToken(c, client, u, grant.Family, scope, oauth.GrantRefreshToken)
+	if !ok {
+		return
+	}
+	newRT, newJTI, err := appauth.GenerateRefreshToken(h.cfg, h.keys, userID, grant)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "gagal membuat refresh token")
+		return
+	}
+	err = h.ts.RotateRefreshToken(ctx, userID, grant.Family, claims.ID, newJTI, sessionMeta(c), h.cfg.RefreshTokenTTL)
+	switch {
+	case errors.Is(err, appauth.ErrRefreshTokenReused):
+		revokeReusedFamily(c, h.db, h.ts, userID, grant.Family, grant.ClientID)
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "refresh token sudah pernah dipakai, sesi dicabut")
+		return
+	case errors.Is(err, appauth.ErrRefreshTokenInvalid):
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "refresh token sudah tidak berlaku")
+		return
+	case err != nil:
+		oauthError(c, http.StatusInternalServerError, "server_error", "gagal memverifikasi refresh token")
+		return
+	}
+	h.respond(c, client, u, scope, at, newRT, oauth.GrantRefreshToken)
+}
+
+// issue buat access token dan, jika refresh tidak nil, refresh token baru lalu kirim response token.
+// RT disimpan paling akhir agar kegagalan sebelumnya tidak meninggalkan family RT yang tidak pernah diterima client.
+func (h *OAuthHandler) issue(c *gin.Context, client *oauth.Client, u user.User, session string, scope user.Scopes, refresh *appauth.RefreshGrant, grant string) {
+	at, ok := h.accessToken(c, client, u, session, scope, grant)
+	if !ok {
+		return
+	}
+	var rt string
+	if refresh != nil {
+		var jti string
+		var err error
+		rt, jti, err = appauth.GenerateRefreshToken(h.cfg, h.keys, u.ID, *refresh)
+		if err != nil {
+			oauthError(c, http.StatusInternalServerError, "server_error", "gagal membuat refresh token")
+			return
+		}
+		if err := h.ts.SaveRefreshToken(c.Request.Context(), u.ID, jti, refresh.Family, sessionMeta(c), h.cfg.RefreshTokenTTL); err != nil {
+			oauthError(c, http.StatusInternalServerError, "server_error", "gagal menyimpan refresh token")
+			return
+		}
+	}
+	h.respond(c, client, u, scope, at, rt, grant)
+}
+
+// accessToken buat access token dan catat untuk denylist. Response error sudah ditulis jika ok false;
+// token yang sudah dicatat tetapi tidak terkirim cukup dibiarkan kedaluwarsa.
+func (h *OAuthHandler) accessToken(c *gin.Context, client *oauth.Client, u user.User, session string, scope user.Scopes, grant string) (string, bool) {
+	sub, err := tokenSubject(h.db.WithContext(c.Request.Context()), u, session, scope)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "gagal membuat access token")
+		return "", false
+	}
+	sub.ClientID = client.ID.String()
+	if grant == oauth.GrantClientCredentials {
+		sub.SessionID = "" // bukan sesi login
+	}
+	at, atJTI, err := appauth.GenerateAccessToken(h.cfg, h.keys, sub)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "gagal membuat access token")
+		return "", false
+	}
+	if err := h.ts.TrackAccessToken(c.Request.Context(), u.ID, session, atJTI, h.cfg.AccessTokenTTL); err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "gagal menyimpan access token")
+		return "", false
+	}
+	return at, true
+}
+
+// respond catat audit lalu kirim response token
+func (h *OAuthHandler) respond(c *gin.Context, client *oauth.Client, u user.User, scope user.Scopes, at, rt, grant string) {
+	db := h.db.WithContext(c.Request.Context())
+	action := audit.ActionLogin
+	if grant == oauth.GrantRefreshToken {
+		action = audit.ActionRefresh
+	}
+	if err := recordAudit(db, c, auditEntry{Action: action, EntityType: entityUser, EntityID: u.ID.String(), ActorID: u.ID, After: gin.H{
+		"client_id":  client.ID.String(),
+		"grant_type": grant,
+		"scope":      scope.String(),
+	}}); err != nil {
+		log.Println("audit auth event gagal:", err)
+	}
+
+	c.JSON(http.StatusOK, oauthTokenResp{
+		AccessToken:  at,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.cfg.AccessTokenTTL.Seconds()),
+		RefreshToken: rt,
+		Scope:        scope.String(),
+	})
+}
+
+// authenticateClient autentikasi client lewat HTTP Basic atau form; client public cukup client_id.
+// Response error sudah ditulis jika ok false.
+func (h *OAuthHandler) authenticateClient(c *gin.Context) (*oauth.Client, bool) {
+	id, secret, basic := c.Request.BasicAuth()
+	if basic {
+		// RFC 6749 bagian 2.3.1: kredensial Basic di-encode form-urlencoded
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		id, secret = c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+	client, err := h.findClient(h.db.WithContext(c.Request.Context()), id)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "gagal mengambil data client")
+		return nil, false
+	}
+	valid := client != nil
+	if valid && client.Public {
+		valid = secret == ""
+	} else if valid {
+		valid = client.VerifySecret(secret)
+	}
+	if !valid {
+		if basic {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		oauthError(c, http.StatusUnauthorized, "invalid_client", "autentikasi client gagal")
+		return nil, false
+	}
+	return client, true
+}
+
+// findClient cari client berdasarkan client_id; nil jika tidak ada
+func (h *OAuthHandler) findClient(db *gorm.DB, clientID string) (*oauth.Client, error) {
+	id, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, nil
+	}
+	var client oauth.Client
+	if err := db.Where("id = ?", id).Limit(1).Find(&client).Error; err != nil {
+		return nil, err
+	}
+	// client nonaktif diperlakukan seperti tidak terdaftar
+	if client.ID == uuid.Nil || client.Disabled {
+		return nil, nil
+	}
+	return &client, nil
+}
+
+// activeUser ambil user pemilik grant; user yang dihapus atau dinonaktifkan ditolak dengan invalid_grant
+func (h *OAuthHandler) activeUser(c *gin.Context, userID string) (user.User, bool) {
+	var u user.User
+	if err := h.db.WithContext(c.Request.Context()).Where("id = ?", userID).Limit(1).Find(&u).Error; err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "gagal mengambil data user")
+		return u, false
+	}
+	if u.ID == uuid.Nil || u.Disabled {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "user tidak aktif")
+		return u, false
+	}
+	return u, true
+}
+
+// grantScope scope yang diminta harus bagian dari allowed; kosong berarti semua allowed
+func grantScope(c *gin.Context, raw string, allowed user.Scopes) (user.Scopes, bool) {
+	scope := allowed
+	if raw != "" {
+		scope = user.ParseScopes(raw)
+		if !allowed.Contains(scope) {
+			oauthError(c, http.StatusBadRequest, "invalid_scope", "scope di luar scope yang diizinkan untuk client")
+			return nil, false
+		}
+	}
+	if len(scope) == 0 {
+		oauthError(c, http.StatusBadRequest, "invalid_scope", "tidak ada scope yang bisa diberikan")
+		return nil, false
+	}
+	return scope, true
+}
+
+// clientSession label sesi untuk access token client_credentials di TokenStore
+func clientSession(clientID uuid.UUID) string {
+	return "client:" + clientID.String()
+}
+
+func oauthError(c *gin.Context, status int, code, desc string) {
+	c.JSON(status, oauthErrorResp{Error: code, ErrorDescription: desc})
+}
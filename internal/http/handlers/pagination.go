@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/pkg/cursor"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageParams hasil parsing query page/page_size atau limit/offset
+type pageParams struct {
+	Page     int
+	PageSize int
+	Offset   int
+	// byOffset true jika client memakai limit/offset, link next/prev ikut format ini
+	byOffset bool
+}
+
+// pageResp envelope untuk response list yang dipaginasi
+type pageResp struct {
+	Data     interface{} `json:"data"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Next     *string     `json:"next"`
+	Prev     *string     `json:"prev"`
+}
+
+// parsePage membaca page/page_size, atau limit/offset jika salah satunya dikirim
+func parsePage(c *gin.Context) (pageParams, error) {
+	if c.Query("limit") != "" || c.Query("offset") != "" {
+		limit, err := queryInt(c, "limit", defaultPageSize)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return pageParams{}, fmt.Errorf("limit harus antara 1 sampai %d", maxPageSize)
+		}
+		offset, err := queryInt(c, "offset", 0)
+		if err != nil || offset < 0 {
+			return pageParams{}, fmt.Errorf("offset tidak valid")
+		}
+		return pageParams{Page: offset/limit + 1, PageSize: limit, Offset: offset, byOffset: true}, nil
+	}
+
+	page, err := queryInt(c, "page", 1)
+	if err != nil || page < 1 {
+		return pageParams{}, fmt.Errorf("page tidak valid")
+	}
+	size, err := queryInt(c, "page_size", defaultPageSize)
+	if err != nil || size < 1 || size > maxPageSize {
+		return pageParams{}, fmt.Errorf("page_size harus antara 1 sampai %d", maxPageSize)
+	}
+	return pageParams{Page: page, PageSize: size, Offset: (page - 1) * size}, nil
+}
+
+// newPageResp menyusun envelope beserta link next/prev dari URL request
+func newPageResp(c *gin.Context, p pageParams, total int64, data interface{}) pageResp {
+	resp := pageResp{Data: data, Total: total, Page: p.Page, PageSize: p.PageSize}
+	if int64(p.Offset+p.PageSize) < total {
+		next := pageLink(c, p, p.Offset+p.PageSize)
+		resp.Next = &next
+	}
+	if p.Offset > 0 {
+		prevOffset := p.Offset - p.PageSize
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		prev := pageLink(c, p, prevOffset)
+		resp.Prev = &prev
+	}
+	return resp
+}
+
+func pageLink(c *gin.Context, p pageParams, offset int) string {
+	q := url.Values{}
+	for k, v := range c.Request.URL.Query() {
+		q[k] = v
+	}
+	if p.byOffset {
+		q.Set("limit", strconv.Itoa(p.PageSize))
+		q.Set("offset", strconv.Itoa(offset))
+	} else {
+		q.Set("page", strconv.Itoa(offset/p.PageSize+1))
+		q.Set("page_size", strconv.Itoa(p.PageSize))
+	}
+	return c.Request.URL.Path + "?" + q.Encode()
+}
+
+// sortField kolom yang boleh dipakai untuk sort beserta tipe postgres-nya
+type sortField struct {
+	Column string
+	Type   string // dipakai untuk CAST nilai cursor
+}
+
+// parseSort mencocokkan param sort (mis. "-price") dengan whitelist kolom
+func parseSort(raw string, allowed map[string]sortField, def string) (string, error) {
+	f, desc, err := lookupSort(raw, allowed, def)
+	if err != nil {
+		return "", err
+	}
+	return f.Column + " " + sortDir(desc), nil
+}
+
+func lookupSort(raw string, allowed map[string]sortField, def string) (sortField, bool, error) {
+	if raw == "" {
+		raw = def
+	}
+	name := strings.TrimPrefix(raw, "-")
+	f, ok := allowed[name]
+	if !ok {
+		return sortField{}, false, fmt.Errorf("sort tidak didukung: %s", name)
+	}
+	return f, strings.HasPrefix(raw, "-"), nil
+}
+
+func sortDir(desc bool) string {
+	if desc {
+		return "desc"
+	}
+	return "asc"
+}
+
+// cursorPage parameter keyset pagination hasil parsing query cursor/sort
+type cursorPage struct {
+	PageSize int
+	Sort     string // nama sort lengkap, mis. "-price"
+	Field    sortField
+	Desc     bool
+	After    *cursor.Cursor
+}
+
+// cursorResp envelope untuk list dengan keyset pagination
+type cursorResp struct {
+	Data       interface{} `json:"data"`
+	PageSize   int         `json:"page_size"`
+	NextCursor *string     `json:"next_cursor"`
+	Next       *string     `json:"next"`
+}
+
+// parseCursorPage aktif jika query berisi cursor atau mode=cursor.
+// Field sort diambil dari cursor sehingga urutan tetap konsisten antar halaman.
+func parseCursorPage(c *gin.Context, secret string, allowed map[string]sortField, def string) (cursorPage, bool, error) {
+	raw := c.Query("cursor")
+	if raw == "" && c.Query("mode") != "cursor" {
+		return cursorPage{}, false, nil
+	}
+	size, err := queryInt(c, "page_size", defaultPageSize)
+	if err != nil || size < 1 || size > maxPageSize {
+		return cursorPage{}, true, fmt.Errorf("page_size harus antara 1 sampai %d", maxPageSize)
+	}
+
+	cp := cursorPage{PageSize: size, Sort: c.Query("sort")}
+	if raw != "" {
+		cur, err := cursor.Decode(secret, raw)
+		if err != nil {
+			return cursorPage{}, true, err
+		}
+		if cp.Sort != "" && cp.Sort != cur.Sort {
+			return cursorPage{}, true, fmt.Errorf("sort tidak sesuai dengan cursor")
+		}
+		cp.Sort = cur.Sort
+		cp.After = &cur
+	}
+	if cp.Sort == "" {
+		cp.Sort = def
+	}
+	cp.Field, cp.Desc, err = lookupSort(cp.Sort, allowed, def)
+	if err != nil {
+		return cursorPage{}, true, err
+	}
+	return cp, true, nil
+}
+
+// apply menambahkan kondisi keyset, order (sort key, id) dan limit+1 untuk deteksi halaman berikutnya
+func (cp cursorPage) apply(q *gorm.DB) *gorm.DB {
+	dir := sortDir(cp.Desc)
+	if cp.After != nil {
+		op := ">"
+		if cp.Desc {
+			op = "<"
+		}
+		cond := fmt.Sprintf("(%s, id) %s (CAST(? AS %s), ?)", cp.Field.Column, op, cp.Field.Type)
+		q = q.Where(cond, cp.After.Value, cp.After.ID)
+	}
+	return q.Order(cp.Field.Column + " " + dir).Order("id " + dir).Limit(cp.PageSize + 1)
+}
+
+// newCursorResp menyusun envelope; lastValue/lastID berasal dari item terakhir halaman ini
+func newCursorResp(c *gin.Context, secret string, cp cursorPage, data interface{}, hasMore bool, lastValue string, lastID uuid.UUID) (cursorResp, error) {
+	resp := cursorResp{Data: data, PageSize: cp.PageSize}
+	if !hasMore {
+		return resp, nil
+	}
+	token, err := cursor.Encode(secret, cursor.Cursor{Sort: cp.Sort, Value: lastValue, ID: lastID})
+	if err != nil {
+		return cursorResp{}, err
+	}
+	q := url.Values{}
+	for k, v := range c.Request.URL.Query() {
+		q[k] = v
+	}
+	q.Del("mode")
+	q.Del("sort")
+	q.Set("cursor", token)
+	next := c.Request.URL.Path + "?" + q.Encode()
+	resp.NextCursor = &token
+	resp.Next = &next
+	return resp, nil
+}
+
+func queryInt(c *gin.Context, key string, def int) (int, error) {
+	v := c.Query(key)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v)
+}
+
+func queryFloat(c *gin.Context, key string) (*float64, error) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const testCursorSecret = "0123456789abcdef0123456789abcdef"
+
+func testContext(target string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c
+}
+
+func TestParseCursorPageRoundTrip(t *testing.T) {
+	c := testContext("/api/books?mode=cursor&sort=-price&page_size=5&q=go")
+	cp, ok, err := parseCursorPage(c, testCursorSecret, bookSortFields, "title")
+	if err != nil || !ok {
+		t.Fatalf("parseCursorPage = %v, %v", ok, err)
+	}
+	if cp.Field.Column != "price" || !cp.Desc || cp.PageSize != 5 || cp.After != nil {
+		t.Fatalf("unexpected first page %+v", cp)
+	}
+
+	lastID := uuid.New()
+	resp, err := newCursorResp(c, testCursorSecret, cp, nil, true, "12.5", lastID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := url.Parse(*resp.Next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := next.Query()
+	if q.Get("mode") != "" || q.Get("sort") != "" || q.Get("q") != "go" || q.Get("cursor") != *resp.NextCursor {
+		t.Fatalf("unexpected next link %s", *resp.Next)
+	}
+
+	// halaman berikutnya mengambil sort dari cursor
+	cp2, ok, err := parseCursorPage(testContext(*resp.Next), testCursorSecret, bookSortFields, "title")
+	if err != nil || !ok {
+		t.Fatalf("parseCursorPage(next) = %v, %v", ok, err)
+	}
+	if cp2.Sort != "-price" || cp2.After == nil || cp2.After.Value != "12.5" || cp2.After.ID != lastID {
+		t.Fatalf("unexpected next page %+v", cp2)
+	}
+
+	last, err := newCursorResp(c, testCursorSecret, cp, nil, false, "", uuid.Nil)
+	if err != nil || last.Next != nil || last.NextCursor != nil {
+		t.Fatalf("last page has a next link: %+v, %v", last, err)
+	}
+}
+
+func TestParseCursorPageErrors(t *testing.T) {
+	c := testContext("/api/books?mode=cursor&sort=price")
+	cp, _, _ := parseCursorPage(c, testCursorSecret, bookSortFields, "title")
+	resp, err := newCursorResp(c, testCursorSecret, cp, nil, true, "10", uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := url.QueryEscape(*resp.NextCursor)
+
+	tests := []struct {
+		name   string
+		target string
+		secret string
+		want   string
+	}{
+		{"tampered cursor", "/api/books?cursor=" + token + "x", testCursorSecret, "cursor tidak valid"},
+		{"other secret", "/api/books?cursor=" + token, strings.Repeat("x", 32), "cursor tidak valid"},
+		{"sort differs from cursor", "/api/books?cursor=" + token + "&sort=title", testCursorSecret, "sort tidak sesuai"},
+		{"unknown sort", "/api/books?mode=cursor&sort=isbn", testCursorSecret, "sort tidak didukung"},
+		{"page size too large", "/api/books?mode=cursor&page_size=101", testCursorSecret, "page_size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok, err := parseCursorPage(testContext(tt.target), tt.secret, bookSortFields, "title")
+			if !ok || err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("parseCursorPage = %v, %v, want error containing %q", ok, err, tt.want)
+			}
+		})
+	}
+
+	if _, ok, err := parseCursorPage(testContext("/api/books?page=2"), testCursorSecret, bookSortFields, "title"); ok || err != nil {
+		t.Fatalf("offset pagination treated as cursor mode: %v, %v", ok, err)
+	}
+}
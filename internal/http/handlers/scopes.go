@@ -0,0 +1,15 @@
+package handlers
+
+import "gorm.io/gorm"
+
+// withUsers scope untuk preload ringkasan user created_by / modified_by
+func withUsers(db *gorm.DB) *gorm.DB {
+	return db.Preload("Creator").Preload("Modifier")
+}
+
+// withBookRelations scope untuk preload author (urut posisi) dan user audit sebuah buku
+func withBookRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Authors", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("position asc")
+	}).Preload("Authors.Author").Scopes(withUsers)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"gorm.io/gorm"
+)
+
+type createAPIKeyReq struct {
+	Name      string     `json:"name" binding:"required,min=1,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"` // opsional, RFC3339
+}
+
+// createAPIKeyResp key lengkap hanya dikirim sekali saat dibuat
+type createAPIKeyResp struct {
+	Key  string      `json:"key"`
+	Data user.APIKey `json:"data"`
+}
+
+// @Summary List my API keys
+// @Description Get the API keys of the authenticated user, newest first. The secret part of a key is never returned.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string][]user.APIKey
+// @Failure 401,403 {object} gin.H
+// @Router /api/users/me/api-keys [get]
+func (h *UserHandler) APIKeys(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	items := []user.APIKey{}
+	if err := db.Where("user_id = ?", c.GetString("userID")).Order("created_at desc").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil daftar API key"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// @Summary Create API key
+// @Description Create a long-lived API key for scripts and integrations. Scopes must be permissions of the user's role.
+// @Description The full key is returned only in this response; send it as X-API-Key or Authorization: Bearer.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param key body createAPIKeyReq true "API key data" example({"name": "import script", "scopes": ["books:read", "books:write"], "expires_at": "2026-12-31T00:00:00Z"})
+// @Success 201 {object} createAPIKeyResp
+// @Failure 400,401,403,404 {object} gin.H
+// @Router /api/users/me/api-keys [post]
+func (h *UserHandler) CreateAPIKey(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req createAPIKeyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "expires_at harus di masa depan"})
+		return
+	}
+	u, ok := h.loadMe(c, db)
+	if !ok {
+		return
+	}
+	scopes, err := user.ValidateScopes(u.Role, req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	raw, prefix, hash, err := user.NewAPIKeySecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat API key"})
+		return
+	}
+	item := user.APIKey{UserID: u.ID, Name: req.Name, Prefix: prefix, SecretHash: hash, Scopes: scopes, ExpiresAt: req.ExpiresAt}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionCreate, EntityType: entityAPIKey, EntityID: item.ID.String(), After: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat API key"})
+		return
+	}
+	c.JSON(http.StatusCreated, createAPIKeyResp{Key: raw, Data: item})
+}
+
+// @Summary Delete API key
+// @Description Revoke one API key of the authenticated user; requests using it are rejected immediately
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "API key ID" format(uuid)
+// @Success 200 {object} gin.H
+// @Failure 400,401,403,404 {object} gin.H
+// @Router /api/users/me/api-keys/{id} [delete]
+func (h *UserHandler) DeleteAPIKey(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "id tidak valid"})
+		return
+	}
+	var item user.APIKey
+	if err := db.First(&item, "id = ? AND user_id = ?", id, c.GetString("userID")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "API key tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus API key"})
+		return
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&user.APIKey{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionDelete, EntityType: entityAPIKey, EntityID: id.String(), Before: item})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menghapus API key"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key berhasil dihapus"})
+}
+
+// revokeAPIKeys hapus semua API key user di dalam tx, dipakai saat password direset atau semua sesi dicabut
+// agar key tidak menjadi jalan masuk yang tersisa. actorID uuid.Nil = user dari context request.
+func revokeAPIKeys(tx *gorm.DB, c *gin.Context, userID, actorID uuid.UUID) error {
+	var keys []user.APIKey
+	if err := tx.Where("user_id = ?", userID).Find(&keys).Error; err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := tx.Delete(&user.APIKey{}, "id = ?", k.ID).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, c, auditEntry{Action: audit.ActionDelete, EntityType: entityAPIKey, EntityID: k.ID.String(), ActorID: actorID, Before: k}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,563 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/config"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"github.com/qullDev/book_API/internal/pkg/actor"
+	appauth "github.com/qullDev/book_API/internal/pkg/auth"
+	"github.com/qullDev/book_API/internal/pkg/notify"
+	"github.com/qullDev/book_API/internal/pkg/password"
+	"github.com/qullDev/book_API/internal/pkg/secret"
+	"gorm.io/gorm"
+)
+
+// passwordResetSendTimeout batas waktu membuat token dan mengirim email reset di background
+const passwordResetSendTimeout = 30 * time.Second
+
+// maxPendingPasswordResets batas pengiriman email reset yang berjalan bersamaan di background
+const maxPendingPasswordResets = 32
+
+// passwordResetIPWindow jangka waktu penghitungan PASSWORD_RESET_IP_MAX
+const passwordResetIPWindow = time.Hour
+
+// UserHandler registrasi dan pengelolaan akun milik user sendiri
+type UserHandler struct {
+	db        *gorm.DB
+	ts        *appauth.TokenStore
+	cfg       *config.Config
+	notifier  notify.Notifier
+	passwords *password.Manager
+	box       *secret.Box   // enkripsi secret MFA
+	resets    chan struct{} // slot pengiriman email reset di background
+}
+
+func NewUserHandler(db *gorm.DB, ts *appauth.TokenStore, cfg *config.Config, notifier notify.Notifier, passwords *password.Manager, box *secret.Box) *UserHandler {
+	return &UserHandler{db: db, ts: ts, cfg: cfg, notifier: notifier, passwords: passwords, box: box,
+		resets: make(chan struct{}, maxPendingPasswordResets)}
+}
+
+type registerReq struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"omitempty,email"`
+}
+
+type updateMeReq struct {
+	Username *string `json:"username"`
+	Email    *string `json:"email"` // string kosong menghapus email
+}
+
+type forgotPasswordReq struct {
+	Login string `json:"login" binding:"required"` // username atau email
+}
+
+type resetPasswordReq struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type changePasswordReq struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// @Summary Register user
+// @Description Create a new account with the reader role. Disabled when ALLOW_REGISTRATION=false.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param registerRequest body registerReq true "Account data" example({"username": "reader01", "password": "rahasia123", "email": "reader01@example.com"})
+// @Success 201 {object} map[string]user.User
+// @Failure 400,403,409 {object} gin.H
+// @Router /api/users/register [post]
+func (h *UserHandler) SignUp(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	if !h.cfg.AllowRegistration {
+		c.JSON(http.StatusForbidden, gin.H{"message": "registrasi tidak dibuka"})
+		return
+	}
+	var req registerReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	if err := user.ValidateUsername(req.Username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := user.ValidatePassword(req.Username, req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if taken, err := usernameTaken(db, req.Username, uuid.Nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal registrasi"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"message": "username sudah dipakai"})
+		return
+	}
+	email := normalizeEmail(req.Email)
+	if taken, err := emailTaken(db, email, uuid.Nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal registrasi"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"message": "email sudah dipakai"})
+		return
+	}
+
+	hashed, err := h.passwords.Hash(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal registrasi"})
+		return
+	}
+	item := user.User{Username: req.Username, Email: email, Password: hashed, Role: user.RoleReader}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionRegister, EntityType: entityUser, EntityID: item.ID.String(), ActorID: item.ID, After: item})
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// username/email diambil request lain di antara pengecekan dan penyimpanan
+		c.JSON(http.StatusConflict, gin.H{"message": duplicateUserMessage(db, item.Username, item.Email, uuid.Nil)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal registrasi"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+// @Summary Get current user
+// @Description Get the account of the authenticated user
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]user.User
+// @Failure 401,404 {object} gin.H
+// @Router /api/users/me [get]
+func (h *UserHandler) Me(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	userID, _ := actor.UserID(c.Request.Context())
+	var item user.User
+	if err := db.Scopes(withUsers).First(&item, "id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "user tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// @Summary Update current user
+// @Description Update the account of the authenticated user
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user body updateMeReq true "Account data" example({"username": "reader02", "email": "reader02@example.com"})
+// @Success 200 {object} map[string]user.User
+// @Failure 400,401,404,409 {object} gin.H
+// @Router /api/users/me [put]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	userID, _ := actor.UserID(c.Request.Context())
+	var req updateMeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	var item user.User
+	if err := db.First(&item, "id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "user tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	before := item
+	if req.Username != nil && *req.Username != item.Username {
+		if err := user.ValidateUsername(*req.Username); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		if taken, err := usernameTaken(db, *req.Username, item.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengupdate user"})
+			return
+		} else if taken {
+			c.JSON(http.StatusConflict, gin.H{"message": "username sudah dipakai"})
+			return
+		}
+		item.Username = *req.Username
+	}
+	if req.Email != nil {
+		email := normalizeEmail(*req.Email)
+		if email != nil && !validEmail(*email) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "email tidak valid"})
+			return
+		}
+		if taken, err := emailTaken(db, email, item.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengupdate user"})
+			return
+		} else if taken {
+			c.JSON(http.StatusConflict, gin.H{"message": "email sudah dipakai"})
+			return
+		}
+		item.Email = email
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user.User{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+			"username": item.Username,
+			"email":    item.Email,
+		}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionUpdate, EntityType: entityUser, EntityID: item.ID.String(), Before: before, After: item})
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"message": duplicateUserMessage(db, item.Username, item.Email, item.ID)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengupdate user"})
+		return
+	}
+	if err := db.Scopes(withUsers).First(&item, "id = ?", item.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// @Summary Change password
+// @Description Change the password of the authenticated user. All refresh and access tokens and API keys of the user are revoked, client_credentials OAuth clients the user owns are disabled, and a pending forced reset is cleared.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param passwordRequest body changePasswordReq true "Current and new password" example({"current_password": "rahasia123", "new_password": "lebihRahasia456"})
+// @Success 200 {object} gin.H "example={'message':'password berhasil diubah'}"
+// @Failure 400,401,404 {object} gin.H
+// @Router /api/users/me/password [post]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	userID, _ := actor.UserID(c.Request.Context())
+	var req changePasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	var item user.User
+	if err := db.First(&item, "id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "user tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return
+	}
+	if ok, _, err := h.passwords.Verify(item.Password, req.CurrentPassword); !ok {
+		if err != nil && err != password.ErrUnknownFormat {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi password"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "password saat ini salah"})
+		return
+	}
+	if err := user.ValidatePassword(item.Username, req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	hashed, err := h.passwords.Hash(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengubah password"})
+		return
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user.User{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+			"password":                hashed,
+			"password_reset_required": false,
+		}).Error; err != nil {
+			return err
+		}
+		if err := revokeAPIKeys(tx, c, item.ID, uuid.Nil); err != nil {
+			return err
+		}
+		if err := disableOwnedClients(tx, c, item.ID, uuid.Nil); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionPassword, EntityType: entityUser, EntityID: item.ID.String()})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengubah password"})
+		return
+	}
+
+	// sesi lain harus login ulang dengan password baru
+	if err := h.ts.RevokeAllTokens(c.Request.Context(), item.ID.String()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "password diubah, tetapi gagal mencabut sesi lama"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "password berhasil diubah"})
+}
+
+// @Summary Forgot password
+// @Description Send a single-use password reset link to the email of the account. Always answers 202 so account existence is not revealed. An account gets at most one email per PASSWORD_RESET_COOLDOWN, and each client IP may send PASSWORD_RESET_IP_MAX requests per hour.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param forgotRequest body forgotPasswordReq true "Username or email" example({"login": "reader01@example.com"})
+// @Success 202 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 429 {object} gin.H "example={'message':'terlalu banyak permintaan reset password, coba lagi nanti','retry_after':1800}"
+// @Router /api/users/password/forgot [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req forgotPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	accepted := gin.H{"message": "jika akun terdaftar dan memiliki email, link reset password telah dikirim"}
+
+	// batas per IP dihitung sebelum user dicari, jadi tidak membocorkan keberadaan akun
+	if h.cfg.PasswordResetIPMax > 0 {
+		n, wait, err := h.ts.CountPasswordResetRequest(c.Request.Context(), c.ClientIP(), passwordResetIPWindow)
+		if err != nil {
+			log.Println("forgot password: gagal menghitung permintaan:", err)
+		} else if n > int64(h.cfg.PasswordResetIPMax) {
+			tooManyRequests(c, wait, "terlalu banyak permintaan reset password, coba lagi nanti")
+			return
+		}
+	}
+
+	login := strings.TrimSpace(req.Login)
+	var item user.User
+	if err := db.Where("username = ? OR email = ?", login, strings.ToLower(login)).First(&item).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Println("forgot password: gagal mencari user:", err)
+		}
+		c.JSON(http.StatusAccepted, accepted)
+		return
+	}
+	if item.Disabled || item.Email == nil {
+		c.JSON(http.StatusAccepted, accepted)
+		return
+	}
+
+	// token, email dan audit diproses di background agar waktu respons akun yang ada
+	// sama dengan akun yang tidak ada; gin.Context tidak boleh dipakai setelah handler selesai.
+	// Jika semua slot terpakai permintaan dilewati, user bisa meminta ulang.
+	select {
+	case h.resets <- struct{}{}:
+		go func(c *gin.Context) {
+			defer func() { <-h.resets }()
+			h.sendPasswordReset(c, item)
+		}(c.Copy())
+	default:
+		log.Println("forgot password: terlalu banyak pengiriman berjalan, permintaan dilewati")
+	}
+	c.JSON(http.StatusAccepted, accepted)
+}
+
+// sendPasswordReset buat token reset, kirim link ke email user lalu catat audit.
+// Berjalan di goroutine dengan context terpisah dari request, jadi kegagalan hanya dicatat ke log.
+func (h *UserHandler) sendPasswordReset(c *gin.Context, item user.User) {
+	ctx, cancel := context.WithTimeout(context.Background(), passwordResetSendTimeout)
+	defer cancel()
+
+	// satu email per cooldown; dicek di background agar tidak terlihat dari waktu respons
+	first, err := h.ts.ClaimPasswordResetCooldown(ctx, item.ID, h.cfg.PasswordResetCooldown)
+	if err != nil {
+		log.Println("forgot password: gagal mengecek cooldown:", err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		log.Println("forgot password: gagal membuat token:", err)
+		return
+	}
+	if err := h.ts.SavePasswordResetToken(ctx, item.ID, token, h.cfg.PasswordResetTTL); err != nil {
+		log.Println("forgot password: gagal menyimpan token:", err)
+		return
+	}
+	link := token
+	if h.cfg.PasswordResetURL != "" {
+		link = fmt.Sprintf(h.cfg.PasswordResetURL, url.QueryEscape(token))
+	}
+	msg := notify.Message{
+		To:      *item.Email,
+		Subject: "Reset password Book API",
+		Body: fmt.Sprintf("Halo %s,\n\nGunakan link berikut untuk mengganti password Anda:\n%s\n\nLink berlaku %s dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak meminta reset password.",
+			item.Username, link, h.cfg.PasswordResetTTL),
+	}
+	if err := h.notifier.Send(ctx, msg); err != nil {
+		log.Println("forgot password: gagal mengirim notifikasi:", err)
+	}
+	if err := recordAudit(h.db.WithContext(ctx), c, auditEntry{Action: audit.ActionResetReq, EntityType: entityUser, EntityID: item.ID.String(), ActorID: item.ID}); err != nil {
+		log.Println("forgot password: gagal mencatat audit:", err)
+	}
+}
+
+// @Summary Reset password
+// @Description Set a new password with a reset token. The token is single use and all refresh and access tokens and API keys of the user are revoked. The client_credentials OAuth clients the user owns are disabled.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param resetRequest body resetPasswordReq true "Reset token and new password" example({"token": "Xh3...", "new_password": "lebihRahasia456"})
+// @Success 200 {object} gin.H "example={'message':'password berhasil direset'}"
+// @Failure 400 {object} gin.H
+// @Router /api/users/password/reset [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	ctx := c.Request.Context()
+	var req resetPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	invalid := gin.H{"message": "token reset tidak valid atau sudah kedaluwarsa"}
+
+	// cek token dulu tanpa memakainya agar password yang ditolak policy tidak menghanguskan token
+	userID, ok, err := h.ts.LookupPasswordResetToken(ctx, req.Token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses reset password"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+	var item user.User
+	if err := db.First(&item, "id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, invalid)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses reset password"})
+		return
+	}
+	if item.Disabled {
+		c.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+	if err := user.ValidatePassword(item.Username, req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	hashed, err := h.passwords.Hash(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses reset password"})
+		return
+	}
+
+	// GETDEL: hanya satu request yang berhasil memakai token ini
+	consumedID, ok, err := h.ts.ConsumePasswordResetToken(ctx, req.Token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses reset password"})
+		return
+	}
+	if !ok || consumedID != item.ID.String() {
+		c.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user.User{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+			"password":                hashed,
+			"password_reset_required": false,
+		}).Error; err != nil {
+			return err
+		}
+		if err := revokeAPIKeys(tx, c, item.ID, item.ID); err != nil {
+			return err
+		}
+		if err := disableOwnedClients(tx, c, item.ID, item.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionPassword, EntityType: entityUser, EntityID: item.ID.String(), ActorID: item.ID, After: gin.H{"via": "reset_token"}})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memproses reset password"})
+		return
+	}
+	if err := h.ts.RevokeAllTokens(ctx, item.ID.String()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "password direset, tetapi gagal mencabut sesi lama"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "password berhasil direset"})
+}
+
+// usernameTaken cek username sudah dipakai user lain
+func usernameTaken(db *gorm.DB, username string, exceptID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&user.User{}).Where("username = ? AND id <> ?", username, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// emailTaken cek email sudah dipakai user lain; email kosong tidak pernah bentrok
+func emailTaken(db *gorm.DB, email *string, exceptID uuid.UUID) (bool, error) {
+	if email == nil {
+		return false, nil
+	}
+	var count int64
+	err := db.Model(&user.User{}).Where("email = ? AND id <> ?", *email, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// duplicateUserMessage pesan 409 saat unique index users dilanggar; kolom yang bentrok dicek ulang
+func duplicateUserMessage(db *gorm.DB, username string, email *string, exceptID uuid.UUID) string {
+	if taken, _ := usernameTaken(db, username, exceptID); taken {
+		return "username sudah dipakai"
+	}
+	if taken, _ := emailTaken(db, email, exceptID); taken {
+		return "email sudah dipakai"
+	}
+	return "username atau email sudah dipakai"
+}
+
+// normalizeEmail trim + lowercase; string kosong menjadi nil (NULL)
+func normalizeEmail(raw string) *string {
+	v := strings.ToLower(strings.TrimSpace(raw))
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func validEmail(v string) bool {
+	addr, err := mail.ParseAddress(v)
+	return err == nil && addr.Address == v
+}
+
+// randomToken 32 byte acak, base64 url-safe
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"github.com/qullDev/book_API/internal/pkg/actor"
+	"github.com/qullDev/book_API/internal/pkg/password"
+	"github.com/qullDev/book_API/internal/pkg/secret"
+	"github.com/qullDev/book_API/internal/pkg/totp"
+	"gorm.io/gorm"
+)
+
+// errMFAChanged secret pending berubah (setup dipanggil lagi) di tengah konfirmasi
+var errMFAChanged = errors.New("status MFA berubah, ulangi enrollment")
+
+const (
+	recoveryCodeCount = 10
+	// totpSkew toleransi selisih jam client: satu periode sebelum/sesudah
+	totpSkew = 1
+)
+
+type mfaStatusResp struct {
+	Enabled                bool  `json:"enabled"`
+	RequiredByRole         bool  `json:"required_by_role"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+type mfaSetupResp struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // render sebagai QR code
+}
+
+type mfaCodeReq struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type mfaDisableReq struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // kode TOTP atau recovery code
+}
+
+type recoveryCodesResp struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// @Summary Get MFA status
+// @Description Get the two-factor authentication state of the authenticated user
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} mfaStatusResp
+// @Failure 401,404 {object} gin.H
+// @Router /api/users/me/mfa [get]
+func (h *UserHandler) MFAStatus(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	u, ok := h.loadMe(c, db)
+	if !ok {
+		return
+	}
+	required, err := mfaRequired(db, u.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil status MFA"})
+		return
+	}
+	var remaining int64
+	if err := db.Model(&user.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", u.ID).Count(&remaining).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil status MFA"})
+		return
+	}
+	c.JSON(http.StatusOK, mfaStatusResp{Enabled: u.MFAEnabled, RequiredByRole: required, RecoveryCodesRemaining: remaining})
+}
+
+// @Summary Start MFA enrollment
+// @Description Generate a new TOTP secret and provisioning URI. MFA is enabled only after the first code is confirmed.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} mfaSetupResp
+// @Failure 401,404,409 {object} gin.H
+// @Router /api/users/me/mfa/setup [post]
+func (h *UserHandler) MFASetup(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	u, ok := h.loadMe(c, db)
+	if !ok {
+		return
+	}
+	if u.MFAEnabled {
+		c.JSON(http.StatusConflict, gin.H{"message": "MFA sudah aktif"})
+		return
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat secret MFA"})
+		return
+	}
+	sealed, err := h.box.Seal(secret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat secret MFA"})
+		return
+	}
+	if err := db.Model(&user.User{}).Where("id = ?", u.ID).Update("mfa_pending_secret", sealed).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat secret MFA"})
+		return
+	}
+	c.JSON(http.StatusOK, mfaSetupResp{Secret: secret, ProvisioningURI: totp.ProvisioningURI(h.cfg.MFAIssuer, u.Username, secret)})
+}
+
+// @Summary Confirm MFA enrollment
+// @Description Verify the first TOTP code, enable MFA and return recovery codes (shown only once). Refresh the token afterwards to clear a pending MFA requirement.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param code body mfaCodeReq true "TOTP code" example({"code": "123456"})
+// @Success 200 {object} recoveryCodesResp
+// @Failure 400,401,404,409 {object} gin.H
+// @Router /api/users/me/mfa/confirm [post]
+func (h *UserHandler) MFAConfirm(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req mfaCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	u, ok := h.loadMe(c, db)
+	if !ok {
+		return
+	}
+	if u.MFAEnabled {
+		c.JSON(http.StatusConflict, gin.H{"message": "MFA sudah aktif"})
+		return
+	}
+	if u.MFAPendingSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "mulai enrollment lewat /api/users/me/mfa/setup"})
+		return
+	}
+	pending, err := h.box.Open(u.MFAPendingSecret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membaca secret MFA"})
+		return
+	}
+	step, valid := totp.Validate(pending, req.Code, time.Now(), totpSkew)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "kode MFA salah"})
+		return
+	}
+
+	var codes []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// ciphertext disalin apa adanya dari kolom pending
+		res := tx.Model(&user.User{}).Where("id = ? AND mfa_pending_secret = ?", u.ID, u.MFAPendingSecret).Updates(map[string]interface{}{
+			"mfa_enabled":        true,
+			"mfa_secret":         u.MFAPendingSecret,
+			"mfa_pending_secret": "",
+			"mfa_last_step":      step,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errMFAChanged
+		}
+		var err error
+		if codes, err = replaceRecoveryCodes(tx, u.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionMFAEnable, EntityType: entityUser, EntityID: u.ID.String()})
+	})
+	if err != nil {
+		if err == errMFAChanged {
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengaktifkan MFA"})
+		return
+	}
+	c.JSON(http.StatusOK, recoveryCodesResp{Message: "MFA berhasil diaktifkan, simpan recovery code berikut", RecoveryCodes: codes})
+}
+
+// @Summary Disable MFA
+// @Description Disable two-factor authentication. Requires the password and a TOTP or recovery code. Not allowed when the role requires MFA. All refresh and access tokens of the user are revoked, so the user has to log in again.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body mfaDisableReq true "Password and code" example({"password": "rahasia123", "code": "123456"})
+// @Success 200 {object} gin.H
+// @Failure 400,401,404,409 {object} gin.H
+// @Router /api/users/me/mfa/disable [post]
+func (h *UserHandler) MFADisable(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req mfaDisableReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	u, ok := h.loadMe(c, db)
+	if !ok {
+		return
+	}
+	if !u.MFAEnabled {
+		c.JSON(http.StatusConflict, gin.H{"message": "MFA belum aktif"})
+		return
+	}
+	required, err := mfaRequired(db, u.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menonaktifkan MFA"})
+		return
+	}
+	if required {
+		c.JSON(http.StatusConflict, gin.H{"message": "role Anda mewajibkan MFA"})
+		return
+	}
+	if valid, _, err := h.passwords.Verify(u.Password, req.Password); !valid {
+		if err != nil && err != password.ErrUnknownFormat {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi password"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "password salah"})
+		return
+	}
+	if valid, _, err := verifyMFACode(db, h.box, u, req.Code); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi kode MFA"})
+		return
+	} else if !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "kode MFA salah"})
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := clearMFA(tx, u.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{Action: audit.ActionMFADisable, EntityType: entityUser, EntityID: u.ID.String()})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal menonaktifkan MFA"})
+		return
+	}
+	// sesi yang dibuka dengan MFA tidak boleh bertahan setelah faktor kedua dicabut
+	if err := h.ts.RevokeAllTokens(c.Request.Context(), u.ID.String()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "MFA dinonaktifkan, tetapi gagal mencabut sesi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "MFA berhasil dinonaktifkan, silakan login ulang"})
+}
+
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after verifying a TOTP code. Old codes stop working.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param code body mfaCodeReq true "TOTP code" example({"code": "123456"})
+// @Success 200 {object} recoveryCodesResp
+// @Failure 400,401,404,409 {object} gin.H
+// @Router /api/users/me/mfa/recovery-codes [post]
+func (h *UserHandler) MFARecoveryCodes(c *gin.Context) {
+	db := h.db.WithContext(c.Request.Context())
+	var req mfaCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "payload tidak valid", "error": err.Error()})
+		return
+	}
+	u, ok := h.loadMe(c, db)
+	if !ok {
+		return
+	}
+	if !u.MFAEnabled {
+		c.JSON(http.StatusConflict, gin.H{"message": "MFA belum aktif"})
+		return
+	}
+	if valid, err := verifyTOTP(db, h.box, u, req.Code); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi kode MFA"})
+		return
+	} else if !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "kode MFA salah"})
+		return
+	}
+	var codes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, u.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal membuat recovery code"})
+		return
+	}
+	c.JSON(http.StatusOK, recoveryCodesResp{Message: "recovery code baru, kode lama sudah tidak berlaku", RecoveryCodes: codes})
+}
+
+// loadMe ambil user yang sedang login; response error sudah ditulis jika ok false
+func (h *UserHandler) loadMe(c *gin.Context, db *gorm.DB) (user.User, bool) {
+	userID, _ := actor.UserID(c.Request.Context())
+	var u user.User
+	if err := db.First(&u, "id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "user tidak ditemukan"})
+			return u, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil data user"})
+		return u, false
+	}
+	return u, true
+}
+
+// mfaRequired cek kebijakan MFA role; role tanpa setting tidak mewajibkan MFA
+func mfaRequired(db *gorm.DB, role string) (bool, error) {
+	var s user.RoleSetting
+	err := db.Where("role = ?", role).Limit(1).Find(&s).Error
+	return s.MFARequired, err
+}
+
+// verifyTOTP cocokkan kode TOTP lalu catat time step-nya; kode pada step yang sama atau lebih lama ditolak (replay)
+func verifyTOTP(db *gorm.DB, box *secret.Box, u user.User, code string) (bool, error) {
+	key, err := box.Open(u.MFASecret)
+	if err != nil {
+		return false, err
+	}
+	step, ok := totp.Validate(key, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	res := db.Model(&user.User{}).Where("id = ? AND mfa_last_step < ?", u.ID, step).UpdateColumn("mfa_last_step", step)
+	return res.RowsAffected == 1, res.Error
+}
+
+// useRecoveryCode tandai recovery code terpakai; update bersyarat menjamin sekali pakai
+func useRecoveryCode(db *gorm.DB, userID uuid.UUID, code string) (bool, error) {
+	res := db.Model(&user.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+// verifyMFACode terima kode TOTP 6 digit atau recovery code; recovery true jika recovery code yang dipakai
+func verifyMFACode(db *gorm.DB, box *secret.Box, u user.User, code string) (ok, recovery bool, err error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		ok, err = verifyTOTP(db, box, u, code)
+		return ok, false, err
+	}
+	ok, err = useRecoveryCode(db, u.ID, code)
+	return ok, ok, err
+}
+
+// replaceRecoveryCodes hapus recovery code lama lalu buat yang baru; plaintext hanya dikembalikan sekali
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&user.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]user.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := hex.EncodeToString(b)
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		rows = append(rows, user.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)})
+	}
+	return codes, tx.Create(&rows).Error
+}
+
+// clearMFA matikan MFA user beserta recovery code-nya
+func clearMFA(tx *gorm.DB, userID uuid.UUID) error {
+	if err := tx.Model(&user.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"mfa_enabled":        false,
+		"mfa_secret":         "",
+		"mfa_pending_secret": "",
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&user.RecoveryCode{}).Error
+}
+
+// hashRecoveryCode sha256 dari kode yang dinormalisasi (huruf kecil, tanpa strip/spasi)
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
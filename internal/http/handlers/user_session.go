@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/book_API/internal/domain/audit"
+	appauth "github.com/qullDev/book_API/internal/pkg/auth"
+)
+
+// sessionResp satu sesi login; Current true untuk sesi asal access token request ini
+type sessionResp struct {
+	appauth.Session
+	Current bool `json:"current"`
+}
+
+// @Summary List my sessions
+// @Description Get the active sessions (logins with a valid refresh token) of the authenticated user, most recently used first
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string][]sessionResp
+// @Failure 401 {object} gin.H
+// @Router /api/users/me/sessions [get]
+func (h *UserHandler) Sessions(c *gin.Context) {
+	sessions, err := h.ts.ListSessions(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mengambil daftar sesi"})
+		return
+	}
+	current := c.GetString("sessionID")
+	items := make([]sessionResp, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, sessionResp{Session: s, Current: s.ID == current})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// @Summary Sign out a session
+// @Description Revoke one session of the authenticated user: its refresh token and the access tokens issued from it stop working
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} gin.H
+// @Failure 401,404 {object} gin.H
+// @Router /api/users/me/sessions/{id} [delete]
+func (h *UserHandler) DeleteSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("userID")
+	sessionID := c.Param("id")
+	found, err := h.ts.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencabut sesi"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"message": "sesi tidak ditemukan"})
+		return
+	}
+	if err := h.ts.DenySessionAccessTokens(ctx, userID, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencabut access token sesi"})
+		return
+	}
+	if err := recordAudit(h.db.WithContext(ctx), c, auditEntry{Action: audit.ActionLogout, EntityType: entityUser, EntityID: userID, After: gin.H{"scope": "session", "session_id": sessionID}}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "gagal mencatat audit"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "sesi berhasil dicabut"})
+}
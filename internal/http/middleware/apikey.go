@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"github.com/qullDev/book_API/internal/pkg/actor"
+	"gorm.io/gorm"
+)
+
+// AuthMethodAPIKey nilai "authMethod" di context untuk request yang memakai API key
+const AuthMethodAPIKey = "api_key"
+
+// lastUsedInterval jarak minimal antar update last_used_at agar tidak menulis ke database di setiap request
+const lastUsedInterval = time.Minute
+
+// authenticateAPIKey validasi API key lalu isi context seperti access token; role dan status akun
+// dibaca dari database sehingga perubahan role atau penonaktifan user langsung berlaku
+func authenticateAPIKey(c *gin.Context, db *gorm.DB, raw string) {
+	prefix, secret, ok := user.ParseAPIKey(raw)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid API key"})
+		return
+	}
+	db = db.WithContext(c.Request.Context())
+	var key user.APIKey
+	if err := db.Where("prefix = ?", prefix).Limit(1).Find(&key).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi API key"})
+		return
+	}
+	hash := user.HashAPIKeySecret(secret)
+	if key.ID == uuid.Nil || subtle.ConstantTimeCompare([]byte(hash), []byte(key.SecretHash)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid API key"})
+		return
+	}
+	now := time.Now()
+	if key.Expired(now) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "API key has expired"})
+		return
+	}
+
+	var u user.User
+	if err := db.Where("id = ?", key.UserID).Limit(1).Find(&u).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi API key"})
+		return
+	}
+	if u.ID != key.UserID || u.Disabled {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid API key"})
+		return
+	}
+	var setting user.RoleSetting
+	if err := db.Where("role = ?", u.Role).Limit(1).Find(&setting).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi API key"})
+		return
+	}
+
+	// gagal mencatat pemakaian tidak perlu menggagalkan request
+	db.Model(&user.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", key.ID, now.Add(-lastUsedInterval)).
+		Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": c.ClientIP()})
+
+	c.Set("userID", u.ID.String())
+	c.Set("role", u.Role)
+	c.Set("scopes", []string(key.Scopes))
+	c.Set("limitedScope", true)
+	c.Set("passwordReset", u.PasswordResetRequired)
+	c.Set("mfaEnroll", setting.MFARequired && !u.MFAEnabled)
+	c.Set("apiKeyID", key.ID.String())
+	c.Set("authMethod", AuthMethodAPIKey)
+	c.Request = c.Request.WithContext(actor.WithUserID(c.Request.Context(), u.ID))
+	c.Next()
+}
@@ -5,28 +5,75 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/qullDev/book_API/internal/config"
+	"github.com/qullDev/book_API/internal/domain/user"
+	"github.com/qullDev/book_API/internal/pkg/actor"
 	appauth "github.com/qullDev/book_API/internal/pkg/auth"
+	"gorm.io/gorm"
 )
 
 // NewJWTAuth memvalidasi Authorization Bearer token menggunakan helper JWT
-func NewJWTAuth(cfg *config.Config) gin.HandlerFunc {
+// dan menolak token yang jti-nya ada di denylist (logout, ganti password, user dinonaktifkan).
+// API key (header X-API-Key, atau Bearer dengan awalan bk_) juga diterima.
+func NewJWTAuth(cfg *config.Config, keys *appauth.KeyRing, ts *appauth.TokenStore, db *gorm.DB) gin.HandlerFunc {
+	denylist := newDenylistCache(ts, cfg.DenylistCacheTTL)
 	return func(c *gin.Context) {
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			authenticateAPIKey(c, db, key)
+			return
+		}
 		authHeader := c.GetHeader("Authorization")
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "missing or invalid Authorization header"})
 			return
 		}
+		if strings.HasPrefix(parts[1], user.APIKeyPrefix) {
+			authenticateAPIKey(c, db, parts[1])
+			return
+		}
+
+		// hanya access token; refresh token dan token challenge MFA ditolak
+		claims, err := appauth.ParseAccessToken(cfg, keys, parts[1])
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid or expired token"})
+			return
+		}
 
-		claims, err := appauth.ParseToken(cfg, parts[1])
+		userID, err := uuid.Parse(claims.UserID)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid or expired token"})
 			return
 		}
 
-		// simpan userID di context untuk digunakan handler
+		denied, err := denylist.denied(c.Request.Context(), claims.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "gagal memverifikasi token"})
+			return
+		}
+		if denied {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "token has been revoked"})
+			return
+		}
+
+		// simpan userID di context untuk digunakan handler,
+		// dan di context request agar kolom CreatedBy/ModifiedBy terisi saat query memakai WithContext
 		c.Set("userID", claims.UserID)
+		c.Set("role", claims.Role)
+		c.Set("passwordReset", claims.PasswordReset)
+		c.Set("mfaEnroll", claims.MFAEnroll)
+		c.Set("jti", claims.ID)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("tokenExp", claims.ExpiresAt.Time)
+		c.Set("clientID", claims.ClientID)
+		// token lama tanpa claim scope memakai semua permission role sampai kedaluwarsa
+		if claims.Scope != "" {
+			scopes := user.ParseScopes(claims.Scope)
+			c.Set("scopes", []string(scopes))
+			c.Set("limitedScope", !scopes.Contains(user.RoleScopes(claims.Role)))
+		}
+		c.Request = c.Request.WithContext(actor.WithUserID(c.Request.Context(), userID))
 		c.Next()
 	}
 }
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qullDev/book_API/internal/domain/user"
+)
+
+// RequirePermission hanya meneruskan request jika role dari token memiliki semua permission
+// dan scope token (access token atau API key) mengizinkannya; permission yang dideklarasikan
+// route group sekaligus menjadi scope yang dibutuhkan. Dipasang setelah NewJWTAuth.
+func RequirePermission(perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		scopes, scoped := c.Get("scopes")
+		for _, p := range perms {
+			if !user.HasPermission(role, p) || (scoped && !user.Scopes(scopes.([]string)).Grants(p)) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "akses ditolak", "required": p})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireReadWrite pilih permission berdasarkan method: GET/HEAD/OPTIONS butuh read, selain itu write
+func RequireReadWrite(read, write string) gin.HandlerFunc {
+	readMW, writeMW := RequirePermission(read), RequirePermission(write)
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			readMW(c)
+		default:
+			writeMW(c)
+		}
+	}
+}
+
+// RequireAccountReady tolak request selama token menandai user wajib mengganti password
+// atau wajib mengaktifkan MFA; endpoint /api/users/me* tidak memakai middleware ini
+func RequireAccountReady() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("passwordReset") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "password harus diganti terlebih dahulu lewat POST /api/users/me/password"})
+			return
+		}
+		if c.GetBool("mfaEnroll") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "role Anda mewajibkan MFA, aktifkan lewat /api/users/me/mfa/setup"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireInteractive tolak request yang memakai API key; untuk endpoint pengelolaan akun dan kredensial
+// (password, sesi, MFA, API key) yang hanya boleh dilakukan lewat login user
+func RequireInteractive() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") == AuthMethodAPIKey {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "endpoint ini tidak bisa diakses dengan API key"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireFullScope tolak API key, access token milik client OAuth, dan access token yang scope-nya dikurangi saat login;
+// untuk endpoint yang mengubah akun dan kredensial (password, sesi, MFA, API key)
+func RequireFullScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") == AuthMethodAPIKey {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "endpoint ini tidak bisa diakses dengan API key"})
+			return
+		}
+		if c.GetBool("limitedScope") || c.GetString("clientID") != "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "endpoint ini membutuhkan token dengan semua scope role Anda"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	appauth "github.com/qullDev/book_API/internal/pkg/auth"
+)
+
+// denylistCache cache lokal hasil cek denylist access token agar tidak ke Redis di setiap request.
+// Token yang dicabut lewat instance ini langsung ditolak; yang dicabut di instance lain paling lambat setelah ttl.
+type denylistCache struct {
+	ts  *appauth.TokenStore
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]denylistEntry
+	lastSweep time.Time
+}
+
+type denylistEntry struct {
+	denied  bool
+	expires time.Time
+}
+
+func newDenylistCache(ts *appauth.TokenStore, ttl time.Duration) *denylistCache {
+	d := &denylistCache{ts: ts, ttl: ttl, entries: map[string]denylistEntry{}}
+	if ttl > 0 {
+		ts.OnDeny(d.markDenied)
+	}
+	return d
+}
+
+// markDenied timpa hasil cache untuk jti yang baru dicabut
+func (d *denylistCache) markDenied(jti string) {
+	now := time.Now()
+	d.mu.Lock()
+	d.entries[jti] = denylistEntry{denied: true, expires: now.Add(d.ttl)}
+	d.sweep(now)
+	d.mu.Unlock()
+}
+
+// denied cek jti di cache, lalu di Redis jika belum ada atau sudah kedaluwarsa
+func (d *denylistCache) denied(ctx context.Context, jti string) (bool, error) {
+	if d.ttl <= 0 {
+		return d.ts.IsAccessTokenDenied(ctx, jti)
+	}
+	now := time.Now()
+	d.mu.Lock()
+	e, ok := d.entries[jti]
+	d.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.denied, nil
+	}
+
+	denied, err := d.ts.IsAccessTokenDenied(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+	d.mu.Lock()
+	// jangan timpa markDenied yang terjadi selama cek ke Redis
+	if cur, ok := d.entries[jti]; ok && cur.denied {
+		denied = true
+	}
+	d.entries[jti] = denylistEntry{denied: denied, expires: now.Add(d.ttl)}
+	d.sweep(now)
+	d.mu.Unlock()
+	return denied, nil
+}
+
+// sweep buang entry kedaluwarsa paling sering sekali per ttl; dipanggil dengan mu terkunci
+func (d *denylistCache) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.ttl {
+		return
+	}
+	for jti, e := range d.entries {
+		if !now.Before(e.expires) {
+			delete(d.entries, jti)
+		}
+	}
+	d.lastSweep = now
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader header yang membawa ID request dari/ke client
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID memakai X-Request-ID dari client (jika wajar) atau membuat yang baru,
+// lalu menyimpannya di context ("requestID") dan header response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = uuid.NewString()
+		}
+		c.Set("requestID", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
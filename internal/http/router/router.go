@@ -5,17 +5,26 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/qullDev/book_API/internal/config"
+	"github.com/qullDev/book_API/internal/domain/user"
 	"github.com/qullDev/book_API/internal/http/handlers"
 	"github.com/qullDev/book_API/internal/http/middleware"
 	appauth "github.com/qullDev/book_API/internal/pkg/auth"
+	"github.com/qullDev/book_API/internal/pkg/notify"
+	"github.com/qullDev/book_API/internal/pkg/password"
+	"github.com/qullDev/book_API/internal/pkg/secret"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"gorm.io/gorm"
 )
 
-func New(db *gorm.DB, cfg *config.Config, ts *appauth.TokenStore) *gin.Engine {
+func New(db *gorm.DB, cfg *config.Config, ts *appauth.TokenStore, notifier notify.Notifier, passwords *password.Manager, guard *appauth.LoginGuard, keys *appauth.KeyRing, box *secret.Box) (*gin.Engine, error) {
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery())
+	// tanpa proxy tepercaya X-Forwarded-For diabaikan, sehingga IP untuk throttle login,
+	// audit dan daftar sesi tidak bisa dipalsukan klien
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+	r.Use(middleware.RequestID(), gin.Logger(), gin.Recovery())
 
 	// Swagger route - pastikan ini ada di atas route lainnya
 	r.GET("/api/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -27,27 +36,91 @@ func New(db *gorm.DB, cfg *config.Config, ts *appauth.TokenStore) *gin.Engine {
 		})
 	})
 
-	// route publik: login & refresh
-	authHandler := handlers.NewAuthHandler(db, ts, cfg)
+	// public key untuk verifikasi token oleh service lain
+	keyHandler := handlers.NewKeyHandler(keys)
+	r.GET("/.well-known/jwks.json", keyHandler.JWKS)
+
+	// route publik: login, refresh, registrasi & reset password
+	authHandler := handlers.NewAuthHandler(db, ts, cfg, passwords, guard, keys, box)
 	r.POST("/api/users/login", authHandler.Login)
+	r.POST("/api/users/login/mfa", authHandler.LoginMFA)
 	r.POST("/api/users/refresh", authHandler.Refresh)
 
+	// OAuth2 token endpoint, client diautentikasi di handler
+	oauthHandler := handlers.NewOAuthHandler(db, ts, cfg, keys, passwords, guard, box)
+	r.POST("/api/oauth/token", oauthHandler.Token)
+	// halaman login & persetujuan authorization_code untuk browser
+	r.GET("/oauth/authorize", oauthHandler.AuthorizePage)
+	r.POST("/oauth/authorize", oauthHandler.AuthorizeSubmit)
+
+	userHandler := handlers.NewUserHandler(db, ts, cfg, notifier, passwords, box)
+	r.POST("/api/users/register", userHandler.SignUp)
+	r.POST("/api/users/password/forgot", userHandler.ForgotPassword)
+	r.POST("/api/users/password/reset", userHandler.ResetPassword)
+
 	// protected dengan JWT
-	jwtMW := middleware.NewJWTAuth(cfg)
+	jwtMW := middleware.NewJWTAuth(cfg, keys, ts, db)
 	api := r.Group("/api", jwtMW)
 
-	// logout (harus bawa AT valid), RT opsional
-	api.POST("/users/logout", authHandler.Logout)
+	// logout (harus bawa AT valid, bukan API key), RT opsional
+	api.POST("/users/logout", middleware.RequireInteractive(), authHandler.Logout)
+
+	// pengelolaan akun & kredensial (password, sesi, MFA, API key) tidak bisa dilakukan
+	// dengan API key atau access token yang scope-nya dikurangi
+	credentialMW := middleware.RequireFullScope()
+
+	// akun milik user sendiri, tidak butuh permission khusus dan tetap bisa dipakai saat wajib ganti password
+	api.GET("/users/me", userHandler.Me)
+	api.PUT("/users/me", credentialMW, userHandler.UpdateMe)
+	api.POST("/users/me/password", credentialMW, userHandler.ChangePassword)
+	api.GET("/users/me/sessions", credentialMW, userHandler.Sessions)
+	api.DELETE("/users/me/sessions/:id", credentialMW, userHandler.DeleteSession)
+	api.GET("/users/me/mfa", credentialMW, userHandler.MFAStatus)
+	api.POST("/users/me/mfa/setup", credentialMW, userHandler.MFASetup)
+	api.POST("/users/me/mfa/confirm", credentialMW, userHandler.MFAConfirm)
+	api.POST("/users/me/mfa/disable", credentialMW, userHandler.MFADisable)
+	api.POST("/users/me/mfa/recovery-codes", credentialMW, userHandler.MFARecoveryCodes)
+
+	// route lain ditolak selama user wajib mengganti password atau mengaktifkan MFA
+	readyMW := middleware.RequireAccountReady()
+
+	// API key untuk akses mesin-ke-mesin
+	api.GET("/users/me/api-keys", credentialMW, readyMW, userHandler.APIKeys)
+	api.POST("/users/me/api-keys", credentialMW, readyMW, userHandler.CreateAPIKey)
+	api.DELETE("/users/me/api-keys/:id", credentialMW, readyMW, userHandler.DeleteAPIKey)
+
+	// persetujuan authorization code OAuth2 oleh user yang sedang login
+	api.POST("/oauth/authorize", credentialMW, readyMW, oauthHandler.Authorize)
+
+	// setiap route group mendeklarasikan permission yang sekaligus menjadi scope wajib token;
+	// audit dan admin membutuhkan scope admin
 
 	// kategori
-	catHandler := handlers.NewCategoryHandler(db)
-	catGroup := api.Group("/categories")
+	catHandler := handlers.NewCategoryHandler(db, cfg)
+	catGroup := api.Group("/categories", readyMW, middleware.RequireReadWrite(user.PermCategoriesRead, user.PermCategoriesWrite))
 	catHandler.Register(catGroup)
 
 	// buku
-	bookHandler := handlers.NewBookHandler(db)
-	bookGroup := api.Group("/books")
+	bookHandler := handlers.NewBookHandler(db, cfg)
+	bookGroup := api.Group("/books", readyMW, middleware.RequireReadWrite(user.PermBooksRead, user.PermBooksWrite))
 	bookHandler.Register(bookGroup)
 
-	return r
+	// author
+	authorHandler := handlers.NewAuthorHandler(db)
+	authorGroup := api.Group("/authors", readyMW, middleware.RequireReadWrite(user.PermAuthorsRead, user.PermAuthorsWrite))
+	authorHandler.Register(authorGroup)
+
+	// audit log
+	auditHandler := handlers.NewAuditHandler(db)
+	auditGroup := api.Group("/audit", readyMW, middleware.RequirePermission(user.PermAuditRead))
+	auditHandler.Register(auditGroup)
+
+	// administrasi user & role
+	adminUserHandler := handlers.NewAdminUserHandler(db, ts, passwords, guard)
+	adminGroup := api.Group("/admin", readyMW, middleware.RequirePermission(user.PermUsersManage))
+	adminUserHandler.Register(adminGroup)
+	// client OAuth menerbitkan kredensial baru, jadi sama seperti API key butuh token penuh
+	oauthHandler.RegisterClients(adminGroup.Group("/oauth-clients", credentialMW))
+
+	return r, nil
 }
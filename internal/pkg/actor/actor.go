@@ -0,0 +1,23 @@
+package actor
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ctxKey struct{}
+
+// WithUserID simpan ID user yang sedang login di context request
+func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, ctxKey{}, userID)
+}
+
+// UserID ambil ID user dari context; ok false jika request tidak terautentikasi
+func UserID(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	id, ok := ctx.Value(ctxKey{}).(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
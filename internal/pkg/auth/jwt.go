@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -8,56 +9,139 @@ import (
 	"github.com/qullDev/book_API/internal/config"
 )
 
+// Jenis token pada claim typ; setiap jenis punya fungsi parse sendiri yang menolak jenis lain
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+	TypeMFA     = "mfa" // token challenge MFA
+)
+
 // Claims = isi token
 type Claims struct {
-	UserID string `json:"sub"` // subject = user ID
+	UserID        string `json:"sub"`                  // subject = user ID
+	Type          string `json:"typ"`                  // jenis token, lihat Type*
+	Role          string `json:"role,omitempty"`       // hanya di access token
+	PasswordReset bool   `json:"pwd_reset,omitempty"`  // user wajib ganti password dulu
+	MFAEnroll     bool   `json:"mfa_enroll,omitempty"` // role mewajibkan MFA tetapi user belum enroll
+	Family        string `json:"fid,omitempty"`        // hanya di refresh token: family hasil satu login
+	SessionID     string `json:"sid,omitempty"`        // hanya di access token: family RT (sesi) asal token
+	Scope         string `json:"scope,omitempty"`      // scope OAuth dipisah spasi; di RT/token MFA kosong = semua scope role
+	ClientID      string `json:"client_id,omitempty"`  // client OAuth penerima token; kosong untuk login lewat /api/users/login
 	jwt.RegisteredClaims
 }
 
-// GenerateAccessToken buat Access Token
-func GenerateAccessToken(cfg *config.Config, userID uuid.UUID) (string, error) {
+// Subject data user yang dimasukkan ke access token
+type Subject struct {
+	UserID        uuid.UUID
+	Role          string
+	PasswordReset bool
+	MFAEnroll     bool
+	SessionID     string
+	Scope         string // scope yang diberikan, dipisah spasi
+	ClientID      string // client OAuth, opsional
+}
+
+// RefreshGrant isi refresh token selain user
+type RefreshGrant struct {
+	Family   string // sama untuk semua RT hasil rotasi dari satu login
+	Scope    string // batas scope access token yang bisa diminta dengan RT ini; kosong = semua scope role
+	ClientID string // client OAuth pemilik RT; kosong untuk RT dari /api/users/login
+}
+
+// GenerateAccessToken buat Access Token; role disertakan sebagai claim untuk otorisasi.
+// jti dikembalikan agar token bisa dicatat dan dicabut lewat denylist.
+func GenerateAccessToken(cfg *config.Config, keys *KeyRing, sub Subject) (string, string, error) {
+	jti := uuid.New().String()
+
+	claims := &Claims{
+		UserID:        sub.UserID.String(),
+		Role:          sub.Role,
+		PasswordReset: sub.PasswordReset,
+		MFAEnroll:     sub.MFAEnroll,
+		SessionID:     sub.SessionID,
+		Scope:         sub.Scope,
+		ClientID:      sub.ClientID,
+		Type:          TypeAccess,
+		// access token untuk resource server (aud = JWTAudience)
+		RegisteredClaims: registered(cfg, cfg.JWTAudience, cfg.AccessTokenTTL, jti),
+	}
+
+	signed, err := keys.sign(claims)
+	return signed, jti, err
+}
+
+// GenerateMFAToken buat token challenge MFA setelah password benar; hanya bisa ditukar
+// dengan token pair lewat verifikasi kode, ditolak sebagai bearer token. scope yang diminta saat login ikut dibawa.
+func GenerateMFAToken(cfg *config.Config, keys *KeyRing, userID uuid.UUID, scope string) (string, error) {
 	claims := &Claims{
-		UserID: userID.String(),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.AccessTokenTTL)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ID:        uuid.New().String(), // jti unik
-		},
+		UserID:           userID.String(),
+		Type:             TypeMFA,
+		Scope:            scope,
+		RegisteredClaims: registered(cfg, cfg.JWTIssuer, cfg.MFATokenTTL, uuid.New().String()),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWTSecret))
+	return keys.sign(claims)
 }
 
-// GenerateRefreshToken buat Refresh Token
-func GenerateRefreshToken(cfg *config.Config, userID uuid.UUID) (string, string, error) {
+// GenerateRefreshToken buat Refresh Token; isi grant sama untuk semua RT hasil rotasi dari satu login
+func GenerateRefreshToken(cfg *config.Config, keys *KeyRing, userID uuid.UUID, grant RefreshGrant) (string, string, error) {
 	jti := uuid.New().String()
 
 	claims := &Claims{
-		UserID: userID.String(),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.RefreshTokenTTL)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ID:        jti,
-		},
+		UserID:   userID.String(),
+		Family:   grant.Family,
+		Scope:    grant.Scope,
+		ClientID: grant.ClientID,
+		Type:     TypeRefresh,
+		// refresh token hanya untuk server ini sendiri (aud = issuer), tidak diterima resource server lain
+		RegisteredClaims: registered(cfg, cfg.JWTIssuer, cfg.RefreshTokenTTL, jti),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString([]byte(cfg.JWTSecret))
+	signed, err := keys.sign(claims)
 	return signed, jti, err
 }
 
-// ParseToken untuk verifikasi token
-func ParseToken(cfg *config.Config, tokenStr string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
+// registered claim standar: iss, aud, iat, exp dan jti
+func registered(cfg *config.Config, audience string, ttl time.Duration, jti string) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Issuer:    cfg.JWTIssuer,
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        jti, // jti unik
+	}
+}
+
+// ParseAccessToken verifikasi access token (bearer token)
+func ParseAccessToken(cfg *config.Config, keys *KeyRing, tokenStr string) (*Claims, error) {
+	return parseToken(cfg, keys, tokenStr, TypeAccess, cfg.JWTAudience)
+}
+
+// ParseRefreshToken verifikasi refresh token
+func ParseRefreshToken(cfg *config.Config, keys *KeyRing, tokenStr string) (*Claims, error) {
+	return parseToken(cfg, keys, tokenStr, TypeRefresh, cfg.JWTIssuer)
+}
+
+// ParseMFAToken verifikasi token challenge MFA
+func ParseMFAToken(cfg *config.Config, keys *KeyRing, tokenStr string) (*Claims, error) {
+	return parseToken(cfg, keys, tokenStr, TypeMFA, cfg.JWTIssuer)
+}
+
+// parseToken verifikasi tanda tangan (key dipilih dari kid, algoritma dibatasi KeyRing),
+// exp, iss, aud dan jenis token
+func parseToken(cfg *config.Config, keys *KeyRing, tokenStr, typ, audience string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := keys.parse(tokenStr, claims,
+		jwt.WithIssuer(cfg.JWTIssuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return nil, err
 	}
-
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	if claims.Type != typ {
+		return nil, fmt.Errorf("jenis token %q, seharusnya %q", claims.Type, typ)
 	}
-	return nil, err
+	return claims, nil
 }
@@ -0,0 +1,362 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/qullDev/book_API/internal/config"
+	"github.com/qullDev/book_API/internal/pkg/secret"
+	"gorm.io/gorm"
+)
+
+// Algoritma tanda tangan token yang didukung
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+const rsaKeyBits = 2048
+
+// SigningKey key tanda tangan JWT yang dibagi semua instance lewat database.
+// Key mulai dipakai menandatangani token sejak NotBefore, tetapi sudah dipublikasikan
+// di JWKS sebelumnya agar service lain sempat memperbarui cache JWKS-nya.
+type SigningKey struct {
+	KID        string    `gorm:"column:kid;size:32;primaryKey"`
+	Algorithm  string    `gorm:"size:10;not null"`
+	PrivateKey string    `gorm:"type:text;not null"` // PKCS#8 PEM, dienkripsi dengan KEK (secret.Box)
+	NotBefore  time.Time `gorm:"not null;index"`
+	CreatedAt  time.Time
+}
+
+func (SigningKey) TableName() string {
+	return "jwt_signing_keys"
+}
+
+// KeyRing key tanda tangan aktif dan key lama yang masih diterima saat verifikasi.
+// Mode HS256 memakai JWTSecret saja (tanpa kid dan tanpa JWKS).
+type KeyRing struct {
+	db          *gorm.DB
+	box         *secret.Box
+	alg         string
+	secret      []byte
+	rotateEvery time.Duration
+	prepublish  time.Duration
+	overlap     time.Duration
+
+	mu   sync.RWMutex
+	keys map[string]*ringKey
+}
+
+type ringKey struct {
+	kid       string
+	alg       string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	notBefore time.Time
+}
+
+// NewKeyRing siapkan key dari konfigurasi; untuk RS256/EdDSA key dibuat di database jika belum ada.
+// Panggil setelah migrasi database.
+func NewKeyRing(db *gorm.DB, cfg *config.Config, box *secret.Box) (*KeyRing, error) {
+	k := &KeyRing{
+		db:          db,
+		box:         box,
+		alg:         cfg.JWTSigningAlg,
+		secret:      []byte(cfg.JWTSecret),
+		rotateEvery: cfg.JWTKeyRotation,
+		prepublish:  cfg.JWTKeyPrepublish,
+		overlap:     cfg.JWTKeyOverlap,
+	}
+	switch k.alg {
+	case AlgHS256:
+		return k, nil
+	case AlgRS256, AlgEdDSA:
+	default:
+		return nil, fmt.Errorf("JWT_SIGNING_ALG tidak didukung: %s", k.alg)
+	}
+	if k.rotateEvery <= k.prepublish {
+		return nil, errors.New("JWT_KEY_ROTATION harus lebih lama dari JWT_KEY_PREPUBLISH")
+	}
+	// key lama harus bisa memverifikasi token terlama yang ditandatanganinya
+	if k.overlap < cfg.RefreshTokenTTL {
+		k.overlap = cfg.RefreshTokenTTL
+	}
+	if err := k.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Run jalankan rotasi terjadwal dan muat ulang key dari database secara berkala sampai ctx selesai
+func (k *KeyRing) Run(ctx context.Context, interval time.Duration) {
+	if k.alg == AlgHS256 {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := k.Refresh(ctx); err != nil {
+				log.Println("refresh JWT signing key:", err)
+			}
+		}
+	}
+}
+
+// Refresh buat key berikutnya jika jadwal rotasi sudah dekat, hapus key yang masa overlap-nya habis,
+// lalu muat semua key yang masih berlaku. Aman dipanggil bersamaan dari beberapa instance.
+func (k *KeyRing) Refresh(ctx context.Context) error {
+	var rows []SigningKey
+	err := k.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// satu instance saja yang merotasi dalam satu waktu
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('jwt_signing_keys'))").Error; err != nil {
+			return err
+		}
+		if err := tx.Order("not_before asc").Find(&rows).Error; err != nil {
+			return err
+		}
+		// key lama yang masih tersimpan plaintext dienkripsi sekali
+		for i := range rows {
+			if secret.Sealed(rows[i].PrivateKey) {
+				continue
+			}
+			sealed, err := k.box.Seal(rows[i].PrivateKey)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&SigningKey{}).Where("kid = ?", rows[i].KID).Update("private_key", sealed).Error; err != nil {
+				return err
+			}
+			rows[i].PrivateKey = sealed
+		}
+
+		now := time.Now()
+		var next *SigningKey
+		switch {
+		case len(rows) == 0, rows[len(rows)-1].Algorithm != k.alg:
+			// pertama kali, atau JWT_SIGNING_ALG diganti: key baru langsung aktif
+			next = &SigningKey{NotBefore: now}
+		case now.After(rows[len(rows)-1].NotBefore.Add(k.rotateEvery - k.prepublish)):
+			nb := rows[len(rows)-1].NotBefore.Add(k.rotateEvery)
+			if nb.Before(now) {
+				nb = now
+			}
+			next = &SigningKey{NotBefore: nb}
+		}
+		if next != nil {
+			if err := k.generate(next); err != nil {
+				return err
+			}
+			if err := tx.Create(next).Error; err != nil {
+				return err
+			}
+			rows = append(rows, *next)
+			log.Printf("JWT signing key %s (%s) dibuat, aktif mulai %s", next.KID, next.Algorithm, next.NotBefore.Format(time.RFC3339))
+		}
+
+		// key pensiun begitu penggantinya aktif, dan dihapus setelah overlap lewat
+		var keep []SigningKey
+		for i, r := range rows {
+			if i+1 < len(rows) && !rows[i+1].NotBefore.Add(k.overlap).After(now) {
+				if err := tx.Delete(&SigningKey{}, "kid = ?", r.KID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			keep = append(keep, r)
+		}
+		rows = keep
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return k.load(rows)
+}
+
+// load parse key dari database lalu ganti isi ring
+func (k *KeyRing) load(rows []SigningKey) error {
+	keys := make(map[string]*ringKey, len(rows))
+	for _, r := range rows {
+		rk, err := k.parseSigningKey(r)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", r.KID, err)
+		}
+		keys[r.KID] = rk
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+	return nil
+}
+
+// activeKey key dengan NotBefore terbaru yang sudah lewat
+func (k *KeyRing) activeKey() (*ringKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	var active *ringKey
+	now := time.Now()
+	for _, rk := range k.keys {
+		if !rk.notBefore.After(now) && (active == nil || rk.notBefore.After(active.notBefore)) {
+			active = rk
+		}
+	}
+	if active == nil {
+		return nil, errors.New("tidak ada JWT signing key yang aktif")
+	}
+	return active, nil
+}
+
+// generate isi KID dan private key baru sesuai algoritma ring
+func (k *KeyRing) generate(sk *SigningKey) error {
+	var priv crypto.Signer
+	var err error
+	switch k.alg {
+	case AlgRS256:
+		priv, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgEdDSA:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	sk.KID = hex.EncodeToString(id)
+	sk.Algorithm = k.alg
+	sk.PrivateKey, err = k.box.Seal(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	return err
+}
+
+func (k *KeyRing) parseSigningKey(sk SigningKey) (*ringKey, error) {
+	plain, err := k.box.Open(sk.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(plain))
+	if block == nil {
+		return nil, errors.New("PEM tidak valid")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rk := &ringKey{kid: sk.KID, alg: sk.Algorithm, notBefore: sk.NotBefore}
+	switch p := parsed.(type) {
+	case *rsa.PrivateKey:
+		if sk.Algorithm != AlgRS256 {
+			return nil, errors.New("algoritma tidak cocok dengan key")
+		}
+		rk.method, rk.private = jwt.SigningMethodRS256, p
+	case ed25519.PrivateKey:
+		if sk.Algorithm != AlgEdDSA {
+			return nil, errors.New("algoritma tidak cocok dengan key")
+		}
+		rk.method, rk.private = jwt.SigningMethodEdDSA, p
+	default:
+		return nil, errors.New("tipe key tidak didukung")
+	}
+	return rk, nil
+}
+
+// sign tanda tangani claims dengan key aktif; header kid diisi agar verifier bisa memilih key
+func (k *KeyRing) sign(claims jwt.Claims) (string, error) {
+	if k.alg == AlgHS256 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(k.secret)
+	}
+	active, err := k.activeKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(active.method, claims)
+	token.Header["kid"] = active.kid
+	return token.SignedString(active.private)
+}
+
+// parse verifikasi tanda tangan; algoritma dibatasi milik ring, bukan dipercaya dari header token
+func (k *KeyRing) parse(tokenStr string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	if k.alg == AlgHS256 {
+		return jwt.ParseWithClaims(tokenStr, claims, func(*jwt.Token) (interface{}, error) {
+			return k.secret, nil
+		}, append(opts, jwt.WithValidMethods([]string{AlgHS256}))...)
+	}
+	return jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		k.mu.RLock()
+		rk, ok := k.keys[kid]
+		k.mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("kid tidak dikenal: %q", kid)
+		}
+		// satu kid hanya sah untuk algoritmanya sendiri
+		if t.Method.Alg() != rk.alg {
+			return nil, fmt.Errorf("algoritma %s tidak sesuai dengan key %s", t.Method.Alg(), kid)
+		}
+		return rk.private.Public(), nil
+	}, append(opts, jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}))...)
+}
+
+// JWK satu public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS public key semua key di ring, termasuk key yang belum aktif dan yang masih dalam masa overlap
+func (k *KeyRing) JWKS() []JWK {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	ordered := make([]*ringKey, 0, len(k.keys))
+	for _, rk := range k.keys {
+		ordered = append(ordered, rk)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].notBefore.After(ordered[j].notBefore) })
+
+	keys := make([]JWK, 0, len(ordered))
+	b64 := base64.RawURLEncoding
+	for _, rk := range ordered {
+		jwk := JWK{Kid: rk.kid, Use: "sig", Alg: rk.alg}
+		switch pub := rk.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = b64.EncodeToString(pub.N.Bytes())
+			jwk.E = b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+			jwk.X = b64.EncodeToString(pub)
+		}
+		keys = append(keys, jwk)
+	}
+	return keys
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/qullDev/book_API/internal/pkg/secret"
+)
+
+func testBox(t *testing.T, fill byte) *secret.Box {
+	t.Helper()
+	box, err := secret.New(bytes.Repeat([]byte{fill}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return box
+}
+
+// testKey buat SigningKey terenkripsi tanpa database
+func testKey(t *testing.T, box *secret.Box, alg string, notBefore time.Time) SigningKey {
+	t.Helper()
+	sk := SigningKey{NotBefore: notBefore}
+	if err := (&KeyRing{box: box, alg: alg}).generate(&sk); err != nil {
+		t.Fatal(err)
+	}
+	return sk
+}
+
+func testRing(t *testing.T, box *secret.Box, alg string, rows ...SigningKey) *KeyRing {
+	t.Helper()
+	k := &KeyRing{box: box, alg: alg}
+	if err := k.load(rows); err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func testClaims() *jwt.RegisteredClaims {
+	return &jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+}
+
+func TestKeyRingSignParse(t *testing.T) {
+	box := testBox(t, 1)
+	past := time.Now().Add(-time.Hour)
+	for _, alg := range []string{AlgRS256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			k := testRing(t, box, alg, testKey(t, box, alg, past))
+			tokenStr, err := k.sign(testClaims())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got jwt.RegisteredClaims
+			token, err := k.parse(tokenStr, &got)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if token.Method.Alg() != alg || got.Subject != "user-1" {
+				t.Fatalf("got alg %s subject %q", token.Method.Alg(), got.Subject)
+			}
+		})
+	}
+}
+
+func TestKeyRingHS256(t *testing.T) {
+	k := &KeyRing{alg: AlgHS256, secret: []byte(strings.Repeat("s", 32))}
+	tokenStr, err := k.sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.parse(tokenStr, &jwt.RegisteredClaims{}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	other := &KeyRing{alg: AlgHS256, secret: []byte(strings.Repeat("x", 32))}
+	if _, err := other.parse(tokenStr, &jwt.RegisteredClaims{}); err == nil {
+		t.Fatal("token accepted with a different secret")
+	}
+
+	box := testBox(t, 1)
+	asym := testRing(t, box, AlgEdDSA, testKey(t, box, AlgEdDSA, time.Now().Add(-time.Hour)))
+	edToken, _ := asym.sign(testClaims())
+	if _, err := k.parse(edToken, &jwt.RegisteredClaims{}); err == nil {
+		t.Fatal("HS256 ring accepted an EdDSA token")
+	}
+}
+
+func TestKeyRingAlgorithmPinning(t *testing.T) {
+	box := testBox(t, 1)
+	past := time.Now().Add(-time.Hour)
+	rsaKey := testKey(t, box, AlgRS256, past)
+	edKey := testKey(t, box, AlgEdDSA, past.Add(-time.Hour))
+	k := testRing(t, box, AlgRS256, rsaKey, edKey)
+
+	rsa := k.keys[rsaKey.KID]
+	der, err := x509.MarshalPKIXPublicKey(rsa.private.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"hs256 with public key as secret", func() string {
+			tok := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+			tok.Header["kid"] = rsaKey.KID
+			s, _ := tok.SignedString(pubPEM)
+			return s
+		}},
+		{"alg none", func() string {
+			tok := jwt.NewWithClaims(jwt.SigningMethodNone, testClaims())
+			tok.Header["kid"] = rsaKey.KID
+			s, _ := tok.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return s
+		}},
+		{"rs256 header on eddsa kid", func() string {
+			tok := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims())
+			tok.Header["kid"] = edKey.KID
+			s, _ := tok.SignedString(rsa.private)
+			return s
+		}},
+		{"unknown kid", func() string {
+			tok := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims())
+			tok.Header["kid"] = "unknown"
+			s, _ := tok.SignedString(rsa.private)
+			return s
+		}},
+		{"missing kid", func() string {
+			s, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims()).SignedString(rsa.private)
+			return s
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := k.parse(tt.token(), &jwt.RegisteredClaims{}); err == nil {
+				t.Fatal("token accepted")
+			}
+		})
+	}
+}
+
+func TestKeyRingActiveKey(t *testing.T) {
+	box := testBox(t, 1)
+	now := time.Now()
+	old := testKey(t, box, AlgEdDSA, now.Add(-2*time.Hour))
+	current := testKey(t, box, AlgEdDSA, now.Add(-time.Hour))
+	next := testKey(t, box, AlgEdDSA, now.Add(time.Hour))
+	k := testRing(t, box, AlgEdDSA, old, current, next)
+
+	tokenStr, err := k.sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := jwt.NewParser().ParseUnverified(tokenStr, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := token.Header["kid"]; kid != current.KID {
+		t.Fatalf("signed with kid %v, want %s", kid, current.KID)
+	}
+
+	// key yang belum aktif tetap dipublikasikan, terbaru lebih dulu
+	jwks := k.JWKS()
+	if len(jwks) != 3 || jwks[0].Kid != next.KID || jwks[2].Kid != old.KID {
+		t.Fatalf("unexpected JWKS order: %+v", jwks)
+	}
+	for _, jwk := range jwks {
+		if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.X == "" {
+			t.Fatalf("unexpected JWK: %+v", jwk)
+		}
+	}
+
+	empty := testRing(t, box, AlgEdDSA, next)
+	if _, err := empty.sign(testClaims()); err == nil {
+		t.Fatal("signed without an active key")
+	}
+}
+
+func TestParseSigningKey(t *testing.T) {
+	box := testBox(t, 1)
+	rsaKey := testKey(t, box, AlgRS256, time.Now())
+
+	mislabeled := rsaKey
+	mislabeled.Algorithm = AlgEdDSA
+
+	plaintext := rsaKey
+	plaintext.PrivateKey, _ = box.Open(rsaKey.PrivateKey)
+
+	tests := []struct {
+		name    string
+		box     *secret.Box
+		sk      SigningKey
+		wantErr bool
+	}{
+		{"valid", box, rsaKey, false},
+		{"algorithm does not match key", box, mislabeled, true},
+		{"not encrypted", box, plaintext, true},
+		{"different KEK", testBox(t, 2), rsaKey, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&KeyRing{box: tt.box}).parseSigningKey(tt.sk)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginGuardConfig batas percobaan login gagal
+type LoginGuardConfig struct {
+	MaxUserAttempts int           // gagal per username sebelum akun dikunci
+	MaxIPAttempts   int           // gagal per IP sebelum IP dikunci
+	Window          time.Duration // jangka waktu penghitungan percobaan gagal
+	BaseDelay       time.Duration // jeda setelah gagal pertama, dua kali lipat tiap gagal berikutnya
+	LockoutDuration time.Duration // lama penguncian setelah batas tercapai
+}
+
+// LoginGuard penghitung login gagal per username dan per IP di Redis
+// dengan exponential backoff dan penguncian sementara.
+// Setiap percobaan dipesan (Reserve) sebelum password diverifikasi, sehingga request paralel
+// tidak bisa melewati batas; percobaan yang berhasil dikembalikan lewat Succeed/Release.
+type LoginGuard struct {
+	rdb *redis.Client
+	cfg LoginGuardConfig
+}
+
+// LoginFailure hasil pencatatan login gagal
+type LoginFailure struct {
+	UserLocked bool // username baru saja dikunci
+	IPLocked   bool // IP baru saja dikunci
+	RetryAfter time.Duration
+}
+
+// LoginAttempt satu percobaan login yang sudah dipesan lewat Reserve.
+// Harus diakhiri dengan Fail, Succeed atau Release; pemanggilan berikutnya diabaikan.
+type LoginAttempt struct {
+	g        *LoginGuard
+	username string
+	ip       string
+	done     bool
+}
+
+func NewLoginGuard(rdb *redis.Client, cfg LoginGuardConfig) *LoginGuard {
+	return &LoginGuard{rdb: rdb, cfg: cfg}
+}
+
+// reserveScript tolak jika username/IP sedang dikunci atau kuota percobaan sudah habis
+// (termasuk percobaan yang masih berjalan), selain itu naikkan kedua penghitung.
+// Hasil: 0 = boleh, >0 = sisa kunci (ms), -1 = kuota habis oleh percobaan yang sedang berjalan.
+var reserveScript = redis.NewScript(`
+local wait = 0
+for i = 3, 4 do
+	local ttl = redis.call("PTTL", KEYS[i])
+	if ttl > wait then
+		wait = ttl
+	end
+end
+if wait > 0 then
+	return wait
+end
+for i = 1, 2 do
+	local max = tonumber(ARGV[i])
+	if max > 0 and tonumber(redis.call("GET", KEYS[i]) or "0") >= max then
+		return -1
+	end
+end
+for i = 1, 2 do
+	if redis.call("INCR", KEYS[i]) == 1 then
+		redis.call("PEXPIRE", KEYS[i], ARGV[3])
+	end
+end
+return 0
+`)
+
+// failScript pasang backoff sesuai jumlah percobaan, atau kunci dan mulai hitung ulang jika batas tercapai.
+// Hasil: {jeda (ms), 1 jika baru dikunci}.
+var failScript = redis.NewScript(`
+local n = tonumber(redis.call("GET", KEYS[1]) or "1")
+local max = tonumber(ARGV[1])
+local base, lockout = tonumber(ARGV[2]), tonumber(ARGV[3])
+local wait, locked = lockout, 0
+if max > 0 and n >= max then
+	locked = 1
+	redis.call("DEL", KEYS[1])
+else
+	wait = base
+	for i = 2, n do
+		if wait >= lockout then
+			break
+		end
+		wait = wait * 2
+	end
+	if wait > lockout then
+		wait = lockout
+	end
+end
+if wait > 0 then
+	redis.call("SET", KEYS[2], "1", "PX", wait)
+end
+return {wait, locked}
+`)
+
+// releaseScript kembalikan satu percobaan yang dipesan tanpa menghapus sisa hitungan
+var releaseScript = redis.NewScript(`
+for i = 1, #KEYS do
+	if tonumber(redis.call("GET", KEYS[i]) or "0") > 0 then
+		redis.call("DECR", KEYS[i])
+	end
+end
+return 1
+`)
+
+// Reserve pesan satu percobaan login untuk username dan IP sebelum password diverifikasi.
+// Jika wait > 0 percobaan ditolak dan attempt nil; tunggu selama wait lalu coba lagi.
+func (g *LoginGuard) Reserve(ctx context.Context, username, ip string) (attempt *LoginAttempt, wait time.Duration, err error) {
+	name := normalizeUsername(username)
+	keys := []string{countKey("u", name), countKey("ip", ip), lockKey("u", name), lockKey("ip", ip)}
+	res, err := reserveScript.Run(ctx, g.rdb, keys,
+		g.cfg.MaxUserAttempts, g.cfg.MaxIPAttempts, g.cfg.Window.Milliseconds()).Int64()
+	if err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case res > 0:
+		return nil, time.Duration(res) * time.Millisecond, nil
+	case res < 0:
+		// percobaan lain masih berjalan; hasilnya akan memasang kunci atau mengembalikan kuota
+		return nil, g.busyDelay(), nil
+	}
+	return &LoginAttempt{g: g, username: name, ip: ip}, 0, nil
+}
+
+// Fail catat percobaan sebagai gagal lalu pasang backoff atau kunci jika batas tercapai
+func (a *LoginAttempt) Fail(ctx context.Context) (LoginFailure, error) {
+	var res LoginFailure
+	if a.done {
+		return res, nil
+	}
+	a.done = true
+	userWait, userLocked, err := a.g.fail(ctx, "u", a.username, a.g.cfg.MaxUserAttempts)
+	if err != nil {
+		return res, err
+	}
+	ipWait, ipLocked, err := a.g.fail(ctx, "ip", a.ip, a.g.cfg.MaxIPAttempts)
+	if err != nil {
+		return res, err
+	}
+	res.UserLocked, res.IPLocked = userLocked, ipLocked
+	res.RetryAfter = userWait
+	if ipWait > res.RetryAfter {
+		res.RetryAfter = ipWait
+	}
+	return res, nil
+}
+
+// Succeed reset penghitung username setelah login berhasil dan kembalikan percobaan ke kuota IP.
+// Sisa hitungan IP dibiarkan agar satu akun valid tidak bisa dipakai untuk mereset percobaan terhadap akun lain.
+func (a *LoginAttempt) Succeed(ctx context.Context) error {
+	if a.done {
+		return nil
+	}
+	a.done = true
+	if err := a.g.Unlock(ctx, a.username); err != nil {
+		return err
+	}
+	return releaseScript.Run(ctx, a.g.rdb, []string{countKey("ip", a.ip)}).Err()
+}
+
+// Release kembalikan percobaan ke kuota username dan IP tanpa mereset penghitung,
+// mis. saat password benar tetapi login belum selesai (menunggu kode MFA) atau request gagal diproses.
+// Aman dipanggil lewat defer setelah Fail/Succeed.
+func (a *LoginAttempt) Release(ctx context.Context) error {
+	if a == nil || a.done {
+		return nil
+	}
+	a.done = true
+	return releaseScript.Run(ctx, a.g.rdb, []string{countKey("u", a.username), countKey("ip", a.ip)}).Err()
+}
+
+// Unlock hapus penghitung dan kunci username (dipakai admin)
+func (g *LoginGuard) Unlock(ctx context.Context, username string) error {
+	name := normalizeUsername(username)
+	return g.rdb.Del(ctx, countKey("u", name), lockKey("u", name)).Err()
+}
+
+func (g *LoginGuard) fail(ctx context.Context, kind, id string, max int) (time.Duration, bool, error) {
+	res, err := failScript.Run(ctx, g.rdb, []string{countKey(kind, id), lockKey(kind, id)},
+		max, g.cfg.BaseDelay.Milliseconds(), g.cfg.LockoutDuration.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	if res[0] <= 0 {
+		return 0, false, nil
+	}
+	return time.Duration(res[0]) * time.Millisecond, res[1] == 1, nil
+}
+
+// busyDelay jeda saat kuota habis oleh percobaan yang masih berjalan
+func (g *LoginGuard) busyDelay() time.Duration {
+	if g.cfg.BaseDelay > 0 {
+		return g.cfg.BaseDelay
+	}
+	return time.Second
+}
+
+func countKey(kind, id string) string {
+	return fmt.Sprintf("lf:%s:%s", kind, id)
+}
+
+func lockKey(kind, id string) string {
+	return fmt.Sprintf("ll:%s:%s", kind, id)
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestGuard(t *testing.T, cfg LoginGuardConfig) (*LoginGuard, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewLoginGuard(rdb, cfg), mr
+}
+
+var testGuardConfig = LoginGuardConfig{
+	MaxUserAttempts: 3,
+	MaxIPAttempts:   5,
+	Window:          15 * time.Minute,
+	BaseDelay:       time.Second,
+	LockoutDuration: time.Minute,
+}
+
+func mustReserve(t *testing.T, g *LoginGuard, username, ip string) *LoginAttempt {
+	t.Helper()
+	a, wait, err := g.Reserve(context.Background(), username, ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait > 0 {
+		t.Fatalf("Reserve(%q, %q) throttled for %s", username, ip, wait)
+	}
+	return a
+}
+
+func TestLoginGuardBackoffAndLockout(t *testing.T) {
+	ctx := context.Background()
+	g, mr := newTestGuard(t, testGuardConfig)
+
+	wantWaits := []time.Duration{time.Second, 2 * time.Second}
+	for i, want := range wantWaits {
+		res, err := mustReserve(t, g, "Alice", "10.0.0.1").Fail(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.UserLocked || res.IPLocked || res.RetryAfter != want {
+			t.Fatalf("failure %d = %+v, want backoff %s", i+1, res, want)
+		}
+		if _, wait, _ := g.Reserve(ctx, "alice", "10.0.0.1"); wait <= 0 || wait > want {
+			t.Fatalf("wait after failure %d = %s, want (0, %s]", i+1, wait, want)
+		}
+		mr.FastForward(want)
+	}
+
+	res, err := mustReserve(t, g, "alice", "10.0.0.1").Fail(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.UserLocked || res.IPLocked || res.RetryAfter != time.Minute {
+		t.Fatalf("third failure = %+v, want user locked for 1m", res)
+	}
+	// kunci username berlaku dari IP lain
+	if _, wait, _ := g.Reserve(ctx, "alice", "10.0.0.2"); wait <= 0 {
+		t.Fatal("locked username accepted from another IP")
+	}
+	// penghitung dimulai ulang setelah kunci berakhir
+	mr.FastForward(time.Minute)
+	res, err = mustReserve(t, g, "alice", "10.0.0.2").Fail(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.UserLocked || res.RetryAfter != time.Second {
+		t.Fatalf("failure after lockout = %+v, want 1s backoff", res)
+	}
+}
+
+func TestLoginGuardBackoffCapped(t *testing.T) {
+	ctx := context.Background()
+	cfg := testGuardConfig
+	cfg.MaxUserAttempts, cfg.MaxIPAttempts = 0, 0
+	cfg.LockoutDuration = 5 * time.Second
+	g, mr := newTestGuard(t, cfg)
+
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		res, err := mustReserve(t, g, "bob", "10.0.0.1").Fail(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.UserLocked || res.RetryAfter != want {
+			t.Fatalf("failure %d = %+v, want backoff %s", i+1, res, want)
+		}
+		mr.FastForward(want)
+	}
+}
+
+func TestLoginGuardIPLockout(t *testing.T) {
+	ctx := context.Background()
+	cfg := testGuardConfig
+	cfg.BaseDelay = 0
+	g, _ := newTestGuard(t, cfg)
+
+	var res LoginFailure
+	for i := 0; i < cfg.MaxIPAttempts; i++ {
+		var err error
+		// username berbeda agar hanya batas IP yang tercapai
+		if res, err = mustReserve(t, g, string(rune('a'+i)), "10.0.0.9").Fail(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !res.IPLocked || res.UserLocked {
+		t.Fatalf("last failure = %+v, want IP locked", res)
+	}
+	if _, wait, _ := g.Reserve(ctx, "zed", "10.0.0.9"); wait <= 0 {
+		t.Fatal("locked IP accepted")
+	}
+	mustReserve(t, g, "zed", "10.0.0.10")
+}
+
+func TestLoginGuardSucceed(t *testing.T) {
+	ctx := context.Background()
+	g, mr := newTestGuard(t, testGuardConfig)
+
+	if _, err := mustReserve(t, g, "carol", "10.0.0.1").Fail(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(time.Second)
+	if err := mustReserve(t, g, "carol", "10.0.0.1").Succeed(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists(countKey("u", "carol")) || mr.Exists(lockKey("u", "carol")) {
+		t.Fatal("user counter not reset after success")
+	}
+	// percobaan yang berhasil dikembalikan, kegagalan sebelumnya tetap dihitung
+	if n, _ := mr.Get(countKey("ip", "10.0.0.1")); n != "1" {
+		t.Fatalf("ip counter = %q, want 1", n)
+	}
+}
+
+func TestLoginGuardRelease(t *testing.T) {
+	ctx := context.Background()
+	g, mr := newTestGuard(t, testGuardConfig)
+
+	a := mustReserve(t, g, "dave", "10.0.0.1")
+	if err := a.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Release setelah Fail/Release diabaikan
+	if err := a.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{countKey("u", "dave"), countKey("ip", "10.0.0.1")} {
+		if n, _ := mr.Get(key); n != "0" {
+			t.Fatalf("%s = %q after release, want 0", key, n)
+		}
+	}
+	var nilAttempt *LoginAttempt
+	if err := nilAttempt.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoginGuardReserveConcurrent(t *testing.T) {
+	ctx := context.Background()
+	g, _ := newTestGuard(t, testGuardConfig)
+
+	// semua percobaan berjalan bersamaan sebelum ada yang dicatat gagal
+	const n = 20
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted []*LoginAttempt
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a, wait, err := g.Reserve(ctx, "erin", "10.0.0.1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if wait == 0 {
+				mu.Lock()
+				accepted = append(accepted, a)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(accepted) != testGuardConfig.MaxUserAttempts {
+		t.Fatalf("accepted %d parallel attempts, want %d", len(accepted), testGuardConfig.MaxUserAttempts)
+	}
+
+	var locked bool
+	for _, a := range accepted {
+		res, err := a.Fail(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locked = locked || res.UserLocked
+	}
+	if !locked {
+		t.Fatal("user not locked after the reserved attempts failed")
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AuthCode data authorization code OAuth2 yang menunggu ditukar di endpoint token
+type AuthCode struct {
+	ClientID      string `json:"client_id"`
+	UserID        string `json:"user_id"`
+	RedirectURI   string `json:"redirect_uri"`
+	Scope         string `json:"scope"`
+	CodeChallenge string `json:"code_challenge"` // PKCE S256
+}
+
+// SaveAuthCode simpan authorization code (sebagai hash) selama ttl
+func (ts *TokenStore) SaveAuthCode(ctx context.Context, code string, data AuthCode, ttl time.Duration) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return ts.rdb.Set(ctx, authCodeKey(code), b, ttl).Err()
+}
+
+// ConsumeAuthCode ambil dan hapus code secara atomik (GETDEL); code hanya bisa ditukar sekali
+func (ts *TokenStore) ConsumeAuthCode(ctx context.Context, code string) (AuthCode, bool, error) {
+	var data AuthCode
+	val, err := ts.rdb.GetDel(ctx, authCodeKey(code)).Bytes()
+	if err == redis.Nil {
+		return data, false, nil
+	}
+	if err != nil {
+		return data, false, err
+	}
+	if err := json.Unmarshal(val, &data); err != nil {
+		return data, false, err
+	}
+	return data, true, nil
+}
+
+func authCodeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return "oac:" + hex.EncodeToString(sum[:])
+}
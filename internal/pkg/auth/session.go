@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SessionMeta data perangkat yang disimpan bersama refresh token
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
+
+// Session satu login aktif (family refresh token) milik user
+type Session struct {
+	ID         string    `json:"id"` // family ID, juga claim sid pada access token
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"` // IP saat terakhir dipakai
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// ListSessions semua sesi aktif user, yang terakhir dipakai lebih dulu
+func (ts *TokenStore) ListSessions(ctx context.Context, userID string) ([]Session, error) {
+	families, err := ts.rdb.SMembers(ctx, sessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	pipe := ts.rdb.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(families))
+	for i, family := range families {
+		cmds[i] = pipe.HGetAll(ctx, familyKey(userID, family))
+	}
+	if len(families) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+	sessions := []Session{}
+	var expired []interface{}
+	for i, family := range families {
+		vals := cmds[i].Val()
+		if vals["jti"] == "" {
+			expired = append(expired, family) // family sudah kedaluwarsa, index belum dibersihkan
+			continue
+		}
+		sessions = append(sessions, Session{
+			ID:         family,
+			UserAgent:  vals["user_agent"],
+			IP:         vals["ip"],
+			CreatedAt:  unixField(vals["created_at"]),
+			LastUsedAt: unixField(vals["last_used_at"]),
+		})
+	}
+	if len(expired) > 0 {
+		if err := ts.rdb.SRem(ctx, sessionsKey(userID), expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession hapus satu sesi beserta refresh token terbarunya; false jika sesi tidak ditemukan
+func (ts *TokenStore) RevokeSession(ctx context.Context, userID, sessionID string) (bool, error) {
+	key := familyKey(userID, sessionID)
+	jti, err := ts.rdb.HGet(ctx, key, "jti").Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	pipe := ts.rdb.TxPipeline()
+	pipe.Del(ctx, refreshKey(userID, jti), key)
+	pipe.SRem(ctx, sessionsKey(userID), sessionID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sessionsIndexedKey penanda bahwa IndexSessions sudah selesai dijalankan
+const sessionsIndexedKey = "rts-indexed"
+
+// IndexSessions masukkan family yang dibuat sebelum index rts:{user} ada ke index tersebut.
+// Cukup sekali; dijalankan saat startup dan dilewati jika penandanya sudah ada.
+func (ts *TokenStore) IndexSessions(ctx context.Context) error {
+	n, err := ts.rdb.Exists(ctx, sessionsIndexedKey).Result()
+	if err != nil || n > 0 {
+		return err
+	}
+	iter := ts.rdb.Scan(ctx, 0, familyKey("*", "*"), 0).Iterator()
+	for iter.Next(ctx) {
+		userID, family, ok := strings.Cut(strings.TrimPrefix(iter.Val(), "rtf:"), ":")
+		if !ok {
+			continue
+		}
+		ttl, err := ts.rdb.PTTL(ctx, iter.Val()).Result()
+		if err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			continue // sudah kedaluwarsa atau terhapus
+		}
+		current, err := ts.rdb.PTTL(ctx, sessionsKey(userID)).Result()
+		if err != nil {
+			return err
+		}
+		pipe := ts.rdb.TxPipeline()
+		pipe.SAdd(ctx, sessionsKey(userID), family)
+		if ttl > current {
+			pipe.PExpire(ctx, sessionsKey(userID), ttl)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return ts.rdb.Set(ctx, sessionsIndexedKey, time.Now().Unix(), 0).Err()
+}
+
+func unixField(v string) time.Time {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(n, 0).UTC()
+}
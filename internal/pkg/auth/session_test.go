@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestStore(t *testing.T) (*TokenStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewTokenStore(rdb), mr
+}
+
+func sessionIDs(t *testing.T, ts *TokenStore, userID string) []string {
+	t.Helper()
+	sessions, err := ts.ListSessions(context.Background(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(sessions))
+	for i, s := range sessions {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+func TestListSessions(t *testing.T) {
+	ctx := context.Background()
+	ts, mr := newTestStore(t)
+	uid := uuid.New()
+
+	if ids := sessionIDs(t, ts, uid.String()); len(ids) != 0 {
+		t.Fatalf("sessions = %v, want none", ids)
+	}
+	if err := ts.SaveRefreshToken(ctx, uid, "jti-a", "fam-a", SessionMeta{UserAgent: "curl", IP: "10.0.0.1"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.SaveRefreshToken(ctx, uid, "jti-b", "fam-b", SessionMeta{UserAgent: "firefox", IP: "10.0.0.2"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	// sesi milik user lain tidak ikut
+	if err := ts.SaveRefreshToken(ctx, uuid.New(), "jti-c", "fam-c", SessionMeta{}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	mr.HSet(familyKey(uid.String(), "fam-a"), "last_used_at", "100")
+	mr.HSet(familyKey(uid.String(), "fam-b"), "last_used_at", "200")
+
+	sessions, err := ts.ListSessions(ctx, uid.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "fam-b" || sessions[1].ID != "fam-a" {
+		t.Fatalf("sessions = %+v, want fam-b then fam-a", sessions)
+	}
+	if s := sessions[0]; s.UserAgent != "firefox" || s.IP != "10.0.0.2" || s.LastUsedAt.Unix() != 200 {
+		t.Fatalf("unexpected session data %+v", s)
+	}
+
+	// family kedaluwarsa dibuang dari index saat daftar sesi dibaca
+	mr.Del(familyKey(uid.String(), "fam-a"))
+	if ids := sessionIDs(t, ts, uid.String()); len(ids) != 1 || ids[0] != "fam-b" {
+		t.Fatalf("sessions = %v, want [fam-b]", ids)
+	}
+	if ok, _ := mr.SIsMember(sessionsKey(uid.String()), "fam-a"); ok {
+		t.Fatal("expired family still indexed")
+	}
+}
+
+func TestSessionIndexTTL(t *testing.T) {
+	ctx := context.Background()
+	ts, mr := newTestStore(t)
+	uid := uuid.New()
+
+	if err := ts.SaveRefreshToken(ctx, uid, "jti-a", "fam-a", SessionMeta{}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(30 * time.Minute)
+	if err := ts.RotateRefreshToken(ctx, uid, "fam-a", "jti-a", "jti-a2", SessionMeta{}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	// index ikut diperpanjang saat rotasi, jadi bertahan selama family terbaru
+	if ttl := mr.TTL(sessionsKey(uid.String())); ttl != time.Hour {
+		t.Fatalf("index TTL = %v, want %v", ttl, time.Hour)
+	}
+	mr.FastForward(45 * time.Minute)
+	if ids := sessionIDs(t, ts, uid.String()); len(ids) != 1 {
+		t.Fatalf("sessions = %v, want [fam-a]", ids)
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	ctx := context.Background()
+	ts, mr := newTestStore(t)
+	uid := uuid.New()
+	for _, fam := range []string{"fam-a", "fam-b"} {
+		if err := ts.SaveRefreshToken(ctx, uid, "jti-"+fam, fam, SessionMeta{}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ok, err := ts.RevokeSession(ctx, uid.String(), "fam-a")
+	if err != nil || !ok {
+		t.Fatalf("RevokeSession = %v, %v", ok, err)
+	}
+	if mr.Exists(refreshKey(uid.String(), "jti-fam-a")) || mr.Exists(familyKey(uid.String(), "fam-a")) {
+		t.Fatal("revoked session keys still exist")
+	}
+	if ids := sessionIDs(t, ts, uid.String()); len(ids) != 1 || ids[0] != "fam-b" {
+		t.Fatalf("sessions = %v, want [fam-b]", ids)
+	}
+	if ok, err := ts.RevokeSession(ctx, uid.String(), "fam-a"); err != nil || ok {
+		t.Fatalf("second RevokeSession = %v, %v, want false", ok, err)
+	}
+
+	// logout dengan RT juga membuang family dari index
+	if err := ts.RevokeRefreshToken(ctx, uid.String(), "jti-fam-b"); err != nil {
+		t.Fatal(err)
+	}
+	if ids := sessionIDs(t, ts, uid.String()); len(ids) != 0 {
+		t.Fatalf("sessions = %v, want none", ids)
+	}
+}
+
+func TestRevokeAllRefreshTokens(t *testing.T) {
+	ctx := context.Background()
+	ts, mr := newTestStore(t)
+	uid, other := uuid.New(), uuid.New()
+	for _, fam := range []string{"fam-a", "fam-b"} {
+		if err := ts.SaveRefreshToken(ctx, uid, "jti-"+fam, fam, SessionMeta{}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ts.RotateRefreshToken(ctx, uid, "fam-a", "jti-fam-a", "jti-fam-a2", SessionMeta{}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.SaveRefreshToken(ctx, other, "jti-x", "fam-x", SessionMeta{}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ts.RevokeAllRefreshTokens(ctx, uid.String()); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{
+		refreshKey(uid.String(), "jti-fam-a2"), refreshKey(uid.String(), "jti-fam-b"),
+		familyKey(uid.String(), "fam-a"), familyKey(uid.String(), "fam-b"), sessionsKey(uid.String()),
+	} {
+		if mr.Exists(key) {
+			t.Errorf("%s still exists", key)
+		}
+	}
+	if ok, _ := ts.VerifyRefreshToken(ctx, other.String(), "jti-x"); !ok {
+		t.Fatal("other user's session revoked")
+	}
+}
+
+func TestIndexSessions(t *testing.T) {
+	ctx := context.Background()
+	ts, mr := newTestStore(t)
+	uid := uuid.New().String()
+
+	// family dari versi lama: tanpa index rts
+	mr.HSet(familyKey(uid, "fam-old"), "jti", "jti-old", "last_used_at", "100")
+	mr.SetTTL(familyKey(uid, "fam-old"), 2*time.Hour)
+	mr.Set(refreshKey(uid, "jti-old"), "fam-old")
+
+	if err := ts.IndexSessions(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ids := sessionIDs(t, ts, uid); len(ids) != 1 || ids[0] != "fam-old" {
+		t.Fatalf("sessions = %v, want [fam-old]", ids)
+	}
+	if ttl := mr.TTL(sessionsKey(uid)); ttl != 2*time.Hour {
+		t.Fatalf("index TTL = %v, want %v", ttl, 2*time.Hour)
+	}
+	if !mr.Exists(sessionsIndexedKey) {
+		t.Fatal("marker not set")
+	}
+
+	// sudah ditandai: family lama berikutnya tidak di-scan lagi
+	mr.HSet(familyKey(uid, "fam-later"), "jti", "jti-later")
+	mr.SetTTL(familyKey(uid, "fam-later"), time.Hour)
+	if err := ts.IndexSessions(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ids := sessionIDs(t, ts, uid); len(ids) != 1 {
+		t.Fatalf("sessions = %v, want only fam-old", ids)
+	}
+}
@@ -2,7 +2,12 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,50 +15,328 @@ import (
 )
 
 type TokenStore struct {
-	rdb *redis.Client
+	rdb    *redis.Client
+	onDeny []func(jti string)
 }
 
 func NewTokenStore(rdb *redis.Client) *TokenStore {
 	return &TokenStore{rdb: rdb}
 }
 
-// SaveRefreshToken simpan RT ke Redis
-func (ts *TokenStore) SaveRefreshToken(ctx context.Context, userID uuid.UUID, jti string, ttl time.Duration) error {
-	key := fmt.Sprintf("rt:%s:%s", userID.String(), jti)
-	return ts.rdb.Set(ctx, key, "valid", ttl).Err()
+// ErrRefreshTokenInvalid RT tidak dikenal: sudah dicabut, kedaluwarsa, atau family-nya sudah dihapus
+var ErrRefreshTokenInvalid = errors.New("refresh token sudah tidak berlaku")
+
+// ErrRefreshTokenReused RT yang sudah dirotasi dipakai lagi; seluruh family sudah dicabut
+var ErrRefreshTokenReused = errors.New("refresh token sudah pernah dipakai")
+
+// Setiap login membuat satu family RT (= satu sesi). Key yang dipakai:
+//
+//	rt:{user}:{jti}  -> family ID, ada selama RT masih berlaku
+//	rtf:{user}:{fid} -> hash sesi: jti RT terbaru, user_agent, ip, created_at, last_used_at
+//	rts:{user}       -> set family ID milik user, index untuk daftar sesi dan logout semua perangkat
+//
+// RT lama yang sudah dirotasi tidak punya key rt lagi, tetapi family-nya masih ada;
+// kondisi itu yang dikenali sebagai reuse.
+
+// SaveRefreshToken simpan RT pertama dari family baru (login) beserta data perangkatnya
+func (ts *TokenStore) SaveRefreshToken(ctx context.Context, userID uuid.UUID, jti, family string, meta SessionMeta, ttl time.Duration) error {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	fkey := familyKey(userID.String(), family)
+	pipe := ts.rdb.TxPipeline()
+	pipe.Set(ctx, refreshKey(userID.String(), jti), family, ttl)
+	pipe.HSet(ctx, fkey, "jti", jti, "user_agent", meta.UserAgent, "ip", meta.IP, "created_at", now, "last_used_at", now)
+	pipe.PExpire(ctx, fkey, ttl)
+	pipe.SAdd(ctx, sessionsKey(userID.String()), family)
+	pipe.PExpire(ctx, sessionsKey(userID.String()), ttl) // sesi terbaru selalu paling lama berlaku
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 // VerifyRefreshToken cek apakah RT masih valid di Redis
 func (ts *TokenStore) VerifyRefreshToken(ctx context.Context, userID, jti string) (bool, error) {
-	key := fmt.Sprintf("rt:%s:%s", userID, jti)
-	val, err := ts.rdb.Get(ctx, key).Result()
-	if err == redis.Nil {
-		return false, nil // tidak ada = invalid
-	}
+	n, err := ts.rdb.Exists(ctx, refreshKey(userID, jti)).Result()
+	return n > 0, err
+}
+
+// rotateScript ganti RT lama dengan yang baru secara atomik dan perbarui data sesi.
+// Jika RT lama sudah tidak ada tetapi family-nya masih hidup, RT itu pernah dirotasi:
+// RT terbaru dan family dihapus. Hasil: 1 = dirotasi, 2 = reuse, 0 = tidak valid.
+var rotateScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	redis.call("DEL", KEYS[1])
+	redis.call("SET", KEYS[3], ARGV[2], "PX", ARGV[3])
+	redis.call("HSET", KEYS[2], "jti", ARGV[1], "last_used_at", ARGV[5], "ip", ARGV[6], "user_agent", ARGV[7])
+	redis.call("HSETNX", KEYS[2], "created_at", ARGV[5])
+	redis.call("PEXPIRE", KEYS[2], ARGV[3])
+	redis.call("SADD", KEYS[4], ARGV[2])
+	redis.call("PEXPIRE", KEYS[4], ARGV[3])
+	return 1
+end
+local current = redis.call("HGET", KEYS[2], "jti")
+if current then
+	redis.call("DEL", ARGV[4] .. current, KEYS[2])
+	redis.call("SREM", KEYS[4], ARGV[2])
+	return 2
+end
+return 0
+`)
+
+// RotateRefreshToken tukar RT oldJTI dengan newJTI di family yang sama; meta perangkat terakhir
+// dan waktu pemakaian dicatat di sesi. Mengembalikan ErrRefreshTokenReused jika oldJTI sudah
+// pernah dirotasi (family ikut dicabut), atau ErrRefreshTokenInvalid jika RT maupun family-nya tidak ada.
+func (ts *TokenStore) RotateRefreshToken(ctx context.Context, userID uuid.UUID, family, oldJTI, newJTI string, meta SessionMeta, ttl time.Duration) error {
+	uid := userID.String()
+	keys := []string{refreshKey(uid, oldJTI), familyKey(uid, family), refreshKey(uid, newJTI), sessionsKey(uid)}
+	res, err := rotateScript.Run(ctx, ts.rdb, keys, newJTI, family, ttl.Milliseconds(), refreshKey(uid, ""),
+		time.Now().Unix(), meta.IP, meta.UserAgent).Int()
 	if err != nil {
-		return false, err
+		return err
+	}
+	switch res {
+	case 1:
+		return nil
+	case 2:
+		return ErrRefreshTokenReused
+	default:
+		return ErrRefreshTokenInvalid
 	}
-	return val == "valid", nil
 }
 
-// RevokeRefreshToken hapus RT dari Redis (logout)
+// RevokeRefreshToken hapus RT dari Redis (logout) beserta family-nya
 func (ts *TokenStore) RevokeRefreshToken(ctx context.Context, userID, jti string) error {
-	key := fmt.Sprintf("rt:%s:%s", userID, jti)
-	return ts.rdb.Del(ctx, key).Err()
+	key := refreshKey(userID, jti)
+	family, err := ts.rdb.GetDel(ctx, key).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pipe := ts.rdb.TxPipeline()
+	pipe.Del(ctx, familyKey(userID, family))
+	pipe.SRem(ctx, sessionsKey(userID), family)
+	_, err = pipe.Exec(ctx)
+	return err
 }
 
-// RevokeAllRefreshTokens hapus semua RT milik user
+// revokeAllScript hapus semua family di index sesi user beserta RT terbarunya secara atomik,
+// agar rotasi yang berjalan bersamaan tidak meninggalkan RT yang masih berlaku
+var revokeAllScript = redis.NewScript(`
+for _, family in ipairs(redis.call("SMEMBERS", KEYS[1])) do
+	local jti = redis.call("HGET", ARGV[1] .. family, "jti")
+	if jti then
+		redis.call("DEL", ARGV[2] .. jti)
+	end
+	redis.call("DEL", ARGV[1] .. family)
+end
+redis.call("DEL", KEYS[1])
+return 1
+`)
+
+// RevokeAllRefreshTokens hapus semua RT dan family milik user
 func (ts *TokenStore) RevokeAllRefreshTokens(ctx context.Context, userID string) error {
-	pattern := fmt.Sprintf("rt:%s:*", userID)
-	iter := ts.rdb.Scan(ctx, 0, pattern, 0).Iterator()
-	var firstErr error
-	for iter.Next(ctx) {
-		if err := ts.rdb.Del(ctx, iter.Val()).Err(); err != nil && firstErr == nil {
-			firstErr = err
+	return revokeAllScript.Run(ctx, ts.rdb, []string{sessionsKey(userID)}, familyKey(userID, ""), refreshKey(userID, "")).Err()
+}
+
+func refreshKey(userID, jti string) string {
+	return fmt.Sprintf("rt:%s:%s", userID, jti)
+}
+
+func familyKey(userID, family string) string {
+	return fmt.Sprintf("rtf:%s:%s", userID, family)
+}
+
+func sessionsKey(userID string) string {
+	return "rts:" + userID
+}
+
+// OnDeny daftarkan fungsi yang dipanggil setiap jti masuk denylist lewat store ini
+// (mis. untuk memperbarui cache lokal). Hanya dipanggil saat setup, sebelum server melayani request.
+func (ts *TokenStore) OnDeny(fn func(jti string)) {
+	ts.onDeny = append(ts.onDeny, fn)
+}
+
+func (ts *TokenStore) notifyDeny(jti string) {
+	for _, fn := range ts.onDeny {
+		fn(jti)
+	}
+}
+
+// TrackAccessToken catat jti access token milik user (beserta sesi asalnya) sampai kedaluwarsa,
+// agar access token user atau satu sesi bisa dimasukkan ke denylist sekaligus
+func (ts *TokenStore) TrackAccessToken(ctx context.Context, userID uuid.UUID, session, jti string, ttl time.Duration) error {
+	key := fmt.Sprintf("ati:%s", userID.String())
+	exp := time.Now().Add(ttl)
+	pipe := ts.rdb.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(exp.Unix()), Member: session + ":" + jti})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(time.Now().Unix(), 10))
+	pipe.Expire(ctx, key, ttl) // token terbaru selalu paling lama berlaku
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// DenyAccessToken masukkan jti access token ke denylist sampai token kedaluwarsa
+func (ts *TokenStore) DenyAccessToken(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil // token sudah kedaluwarsa, tidak perlu disimpan
+	}
+	if err := ts.rdb.Set(ctx, denyKey(jti), "revoked", ttl).Err(); err != nil {
+		return err
+	}
+	ts.notifyDeny(jti)
+	return nil
+}
+
+// DenyAllAccessTokens masukkan semua access token user yang belum kedaluwarsa ke denylist
+func (ts *TokenStore) DenyAllAccessTokens(ctx context.Context, userID string) error {
+	return ts.denyTracked(ctx, userID, func(string) bool { return true })
+}
+
+// DenySessionAccessTokens masukkan access token yang diterbitkan dari satu sesi ke denylist
+func (ts *TokenStore) DenySessionAccessTokens(ctx context.Context, userID, session string) error {
+	return ts.denyTracked(ctx, userID, func(s string) bool { return s == session })
+}
+
+// denyTracked masukkan access token tercatat yang sesinya cocok ke denylist lalu hapus dari catatan
+func (ts *TokenStore) denyTracked(ctx context.Context, userID string, match func(session string) bool) error {
+	key := fmt.Sprintf("ati:%s", userID)
+	now := time.Now()
+	items, err := ts.rdb.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(now.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return err
+	}
+	var denied []string
+	pipe := ts.rdb.Pipeline()
+	for _, z := range items {
+		member, _ := z.Member.(string)
+		session, jti, _ := strings.Cut(member, ":")
+		if !match(session) {
+			continue
 		}
+		// +1 detik: exp token dibulatkan ke bawah saat disimpan sebagai claim
+		ttl := time.Unix(int64(z.Score), 0).Sub(now) + time.Second
+		pipe.Set(ctx, denyKey(jti), "revoked", ttl)
+		pipe.ZRem(ctx, key, member)
+		denied = append(denied, jti)
+	}
+	if len(denied) == 0 {
+		return nil
 	}
-	if err := iter.Err(); err != nil && firstErr == nil {
-		firstErr = err
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	for _, jti := range denied {
+		ts.notifyDeny(jti)
+	}
+	return nil
+}
+
+// RevokeAllTokens cabut semua refresh token user dan masukkan access token-nya ke denylist
+// (logout semua perangkat, ganti/reset password, user dinonaktifkan)
+func (ts *TokenStore) RevokeAllTokens(ctx context.Context, userID string) error {
+	if err := ts.RevokeAllRefreshTokens(ctx, userID); err != nil {
+		return err
+	}
+	return ts.DenyAllAccessTokens(ctx, userID)
+}
+
+// IsAccessTokenDenied cek apakah jti access token ada di denylist
+func (ts *TokenStore) IsAccessTokenDenied(ctx context.Context, jti string) (bool, error) {
+	n, err := ts.rdb.Exists(ctx, denyKey(jti)).Result()
+	return n > 0, err
+}
+
+func denyKey(jti string) string {
+	return "atd:" + jti
+}
+
+// SavePasswordResetToken simpan hash token reset milik user; token reset sebelumnya ikut dicabut
+// sehingga hanya link terbaru yang berlaku
+func (ts *TokenStore) SavePasswordResetToken(ctx context.Context, userID uuid.UUID, token string, ttl time.Duration) error {
+	key := resetTokenKey(token)
+	userKey := fmt.Sprintf("pru:%s", userID.String())
+	prev, err := ts.rdb.GetSet(ctx, userKey, key).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+	if prev != "" && prev != key {
+		if err := ts.rdb.Del(ctx, prev).Err(); err != nil {
+			return err
+		}
+	}
+	if err := ts.rdb.Expire(ctx, userKey, ttl).Err(); err != nil {
+		return err
+	}
+	return ts.rdb.Set(ctx, key, userID.String(), ttl).Err()
+}
+
+// LookupPasswordResetToken baca user pemilik token tanpa memakainya
+func (ts *TokenStore) LookupPasswordResetToken(ctx context.Context, token string) (string, bool, error) {
+	val, err := ts.rdb.Get(ctx, resetTokenKey(token)).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+// ConsumePasswordResetToken ambil dan hapus token secara atomik (GETDEL) sehingga hanya bisa dipakai sekali
+func (ts *TokenStore) ConsumePasswordResetToken(ctx context.Context, token string) (string, bool, error) {
+	val, err := ts.rdb.GetDel(ctx, resetTokenKey(token)).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	ts.rdb.Del(ctx, fmt.Sprintf("pru:%s", val))
+	return val, true, nil
+}
+
+// ClaimPasswordResetCooldown tandai user baru saja dikirimi link reset (SET NX) selama cooldown.
+// false jika permintaan sebelumnya masih dalam masa cooldown.
+func (ts *TokenStore) ClaimPasswordResetCooldown(ctx context.Context, userID uuid.UUID, cooldown time.Duration) (bool, error) {
+	if cooldown <= 0 {
+		return true, nil
+	}
+	return ts.rdb.SetNX(ctx, fmt.Sprintf("pruc:%s", userID.String()), 1, cooldown).Result()
+}
+
+// countScript naikkan penghitung; window dimulai saat permintaan pertama. Hasil: {jumlah, sisa window (ms)}.
+var countScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {n, redis.call("PTTL", KEYS[1])}
+`)
+
+// CountPasswordResetRequest catat permintaan reset password dari ip dalam window;
+// mengembalikan jumlah permintaan sejauh ini dan sisa waktu window
+func (ts *TokenStore) CountPasswordResetRequest(ctx context.Context, ip string, window time.Duration) (int64, time.Duration, error) {
+	res, err := countScript.Run(ctx, ts.rdb, []string{"prip:" + ip}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return res[0], time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// resetTokenKey token disimpan sebagai hash agar isi Redis tidak bisa dipakai langsung
+func resetTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "pr:" + hex.EncodeToString(sum[:])
+}
+
+// UseMFAChallenge tandai jti token challenge MFA sudah dipakai (SETNX) sampai token kedaluwarsa.
+// false jika challenge yang sama sudah pernah berhasil dipakai.
+func (ts *TokenStore) UseMFAChallenge(ctx context.Context, jti string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		return false, nil
 	}
-	return firstErr
+	return ts.rdb.SetNX(ctx, "mfau:"+jti, 1, ttl).Result()
 }
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRotateRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	ts, mr := newTestStore(t)
+	uid := uuid.New()
+	u := uid.String()
+	if err := ts.SaveRefreshToken(ctx, uid, "jti-1", "fam", SessionMeta{IP: "10.0.0.1", UserAgent: "curl"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	meta := SessionMeta{IP: "10.0.0.2", UserAgent: "app"}
+	if err := ts.RotateRefreshToken(ctx, uid, "fam", "jti-1", "jti-2", meta, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists(refreshKey(u, "jti-1")) {
+		t.Fatal("old refresh token still valid")
+	}
+	if fam, _ := mr.Get(refreshKey(u, "jti-2")); fam != "fam" {
+		t.Fatalf("new refresh token family = %q, want fam", fam)
+	}
+	if got := mr.HGet(familyKey(u, "fam"), "jti"); got != "jti-2" {
+		t.Fatalf("session jti = %q, want jti-2", got)
+	}
+	if got := mr.HGet(familyKey(u, "fam"), "ip"); got != "10.0.0.2" {
+		t.Fatalf("session ip = %q, want 10.0.0.2", got)
+	}
+	if ids := sessionIDs(t, ts, u); len(ids) != 1 || ids[0] != "fam" {
+		t.Fatalf("sessions = %v, want [fam]", ids)
+	}
+}
+
+func TestRotateRefreshTokenReuse(t *testing.T) {
+	ctx := context.Background()
+	ts, mr := newTestStore(t)
+	uid := uuid.New()
+	u := uid.String()
+	for _, fam := range []string{"fam", "other"} {
+		if err := ts.SaveRefreshToken(ctx, uid, "jti-"+fam, fam, SessionMeta{}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ts.RotateRefreshToken(ctx, uid, "fam", "jti-fam", "jti-2", SessionMeta{}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// RT yang sudah dirotasi dipakai lagi: family dan RT terbarunya dicabut
+	err := ts.RotateRefreshToken(ctx, uid, "fam", "jti-fam", "jti-3", SessionMeta{}, time.Hour)
+	if !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("err = %v, want ErrRefreshTokenReused", err)
+	}
+	for _, key := range []string{refreshKey(u, "jti-2"), refreshKey(u, "jti-3"), familyKey(u, "fam")} {
+		if mr.Exists(key) {
+			t.Fatalf("%s still exists after reuse", key)
+		}
+	}
+	if ids := sessionIDs(t, ts, u); len(ids) != 1 || ids[0] != "other" {
+		t.Fatalf("sessions = %v, want [other]", ids)
+	}
+
+	// family sudah dicabut: RT terbaru juga tidak berlaku lagi
+	err = ts.RotateRefreshToken(ctx, uid, "fam", "jti-2", "jti-4", SessionMeta{}, time.Hour)
+	if !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("err = %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestRotateRefreshTokenUnknown(t *testing.T) {
+	ts, mr := newTestStore(t)
+	uid := uuid.New()
+	err := ts.RotateRefreshToken(context.Background(), uid, "fam", "jti-1", "jti-2", SessionMeta{}, time.Hour)
+	if !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("err = %v, want ErrRefreshTokenInvalid", err)
+	}
+	if mr.Exists(refreshKey(uid.String(), "jti-2")) {
+		t.Fatal("unknown token was rotated")
+	}
+}
+
+func TestClaimPasswordResetCooldown(t *testing.T) {
+	ctx := context.Background()
+	ts, mr := newTestStore(t)
+	uid, other := uuid.New(), uuid.New()
+
+	claim := func(id uuid.UUID, cooldown time.Duration) bool {
+		t.Helper()
+		ok, err := ts.ClaimPasswordResetCooldown(ctx, id, cooldown)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+	if !claim(uid, time.Minute) {
+		t.Fatal("first request rejected")
+	}
+	if claim(uid, time.Minute) {
+		t.Fatal("second request accepted during cooldown")
+	}
+	if !claim(other, time.Minute) {
+		t.Fatal("cooldown leaked to another user")
+	}
+	mr.FastForward(time.Minute)
+	if !claim(uid, time.Minute) {
+		t.Fatal("request rejected after cooldown")
+	}
+	if !claim(uid, 0) || !claim(uid, 0) {
+		t.Fatal("zero cooldown should not throttle")
+	}
+}
+
+func TestCountPasswordResetRequest(t *testing.T) {
+	ctx := context.Background()
+	ts, mr := newTestStore(t)
+
+	for want := int64(1); want <= 3; want++ {
+		n, wait, err := ts.CountPasswordResetRequest(ctx, "10.0.0.1", time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != want || wait <= 0 || wait > time.Hour {
+			t.Fatalf("request %d = (%d, %s), want (%d, (0, 1h])", want, n, wait, want)
+		}
+		mr.FastForward(10 * time.Minute)
+	}
+	// window tidak diperpanjang oleh permintaan berikutnya
+	if _, wait, _ := ts.CountPasswordResetRequest(ctx, "10.0.0.1", time.Hour); wait > 30*time.Minute {
+		t.Fatalf("window extended to %s", wait)
+	}
+	if n, _, _ := ts.CountPasswordResetRequest(ctx, "10.0.0.2", time.Hour); n != 1 {
+		t.Fatalf("other IP count = %d, want 1", n)
+	}
+	mr.FastForward(time.Hour)
+	if n, _, _ := ts.CountPasswordResetRequest(ctx, "10.0.0.1", time.Hour); n != 1 {
+		t.Fatalf("count after window = %d, want 1", n)
+	}
+}
@@ -0,0 +1,55 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalid dikembalikan jika cursor rusak atau signature tidak cocok
+var ErrInvalid = errors.New("cursor tidak valid")
+
+// Cursor = posisi terakhir pada keyset pagination (sort key + id)
+type Cursor struct {
+	Sort  string    `json:"s"` // field sort, mis. "-price"
+	Value string    `json:"v"` // nilai sort key item terakhir
+	ID    uuid.UUID `json:"i"` // id item terakhir sebagai tie-breaker
+}
+
+// Encode serialisasi cursor menjadi string opaque yang ditandatangani HMAC
+func Encode(secret string, c Cursor) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + sign(secret, body), nil
+}
+
+// Decode verifikasi signature lalu kembalikan isi cursor
+func Decode(secret, s string) (Cursor, error) {
+	body, sig, ok := strings.Cut(s, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(sign(secret, body))) {
+		return Cursor{}, ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return Cursor{}, ErrInvalid
+	}
+	var c Cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return Cursor{}, ErrInvalid
+	}
+	return c, nil
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
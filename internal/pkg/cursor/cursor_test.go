@@ -0,0 +1,62 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestEncodeDecode(t *testing.T) {
+	want := Cursor{Sort: "-price", Value: "59.99", ID: uuid.New()}
+	token, err := Encode(testSecret, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(testSecret, token)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got != want {
+		t.Fatalf("Decode = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	token, err := Encode(testSecret, Cursor{Sort: "title", Value: "Go", ID: uuid.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, sig, _ := strings.Cut(token, ".")
+
+	// payload lain dengan signature asli
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"title","v":"Zzz","i":"00000000-0000-0000-0000-000000000000"}`))
+	// signature valid untuk body yang bukan JSON cursor
+	notJSON := base64.RawURLEncoding.EncodeToString([]byte("bukan json"))
+	notB64 := "!!!"
+
+	tests := []struct {
+		name   string
+		secret string
+		in     string
+	}{
+		{"wrong secret", strings.Repeat("x", 32), token},
+		{"tampered body", testSecret, forged + "." + sig},
+		{"tampered signature", testSecret, body + "." + strings.Repeat("A", len(sig))},
+		{"missing signature", testSecret, body},
+		{"empty", testSecret, ""},
+		{"signed garbage", testSecret, notJSON + "." + sign(testSecret, notJSON)},
+		{"signed invalid base64", testSecret, notB64 + "." + sign(testSecret, notB64)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.secret, tt.in); !errors.Is(err, ErrInvalid) {
+				t.Fatalf("err = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalid dikembalikan jika format atau checksum ISBN salah
+var ErrInvalid = errors.New("isbn tidak valid")
+
+// Normalize validasi ISBN-10 atau ISBN-13 (boleh dengan spasi/tanda hubung)
+// lalu kembalikan bentuk ISBN-13 tanpa pemisah.
+func Normalize(s string) (string, error) {
+	s = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+	switch len(s) {
+	case 10:
+		if !valid10(s) {
+			return "", ErrInvalid
+		}
+		body := "978" + s[:9]
+		return body + string(check13(body)), nil
+	case 13:
+		if !allDigits(s) || (!strings.HasPrefix(s, "978") && !strings.HasPrefix(s, "979")) {
+			return "", ErrInvalid
+		}
+		if check13(s[:12]) != s[12] {
+			return "", ErrInvalid
+		}
+		return s, nil
+	default:
+		return "", ErrInvalid
+	}
+}
+
+// valid10 checksum ISBN-10: jumlah digit x bobot 10..1 habis dibagi 11, digit terakhir boleh X (=10)
+func valid10(s string) bool {
+	if !allDigits(s[:9]) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(s[i]-'0') * (10 - i)
+	}
+	switch last := s[9]; {
+	case last == 'X':
+		sum += 10
+	case last >= '0' && last <= '9':
+		sum += int(last - '0')
+	default:
+		return false
+	}
+	return sum%11 == 0
+}
+
+// check13 hitung digit cek ISBN-13 dari 12 digit pertama (bobot 1,3 bergantian)
+func check13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(body[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"isbn-10 with hyphens", "0-306-40615-2", "9780306406157"},
+		{"isbn-10 with spaces", "0 306 40615 2", "9780306406157"},
+		{"isbn-10 check digit X", "0-8044-2957-X", "9780804429573"},
+		{"isbn-10 lowercase x", "080442957x", "9780804429573"},
+		{"isbn-13 with hyphens", "978-0-306-40615-7", "9780306406157"},
+		{"isbn-13 plain", "9780134190440", "9780134190440"},
+		{"isbn-13 979 prefix", "979-10-200-0000-2", "9791020000002"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.in)
+			if err != nil {
+				t.Fatalf("Normalize(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"isbn-10 bad checksum", "0-306-40615-3"},
+		{"isbn-10 X not last", "0-306-4X615-2"},
+		{"isbn-10 letter", "0-306-40615-A"},
+		{"isbn-13 bad checksum", "978-0-306-40615-8"},
+		{"isbn-13 wrong prefix", "977-0-306-40615-7"},
+		{"isbn-13 with X", "978030640615X"},
+		{"too short", "12345"},
+		{"too long", "97803064061570"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Normalize(tt.in); !errors.Is(err, ErrInvalid) {
+				t.Fatalf("Normalize(%q) err = %v, want ErrInvalid", tt.in, err)
+			}
+		})
+	}
+}
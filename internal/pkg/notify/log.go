@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Log tulis pesan ke file (append) atau ke log standar jika path kosong; untuk development lokal
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	entry := fmt.Sprintf("[%s] to=%s subject=%q\n%s\n---\n", time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	if l.path == "" {
+		log.Print("notify: ", entry)
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(entry); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qullDev/book_API/internal/config"
+)
+
+// Message = satu pesan yang dikirim ke user
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier pengirim pesan ke user (email, log, dll)
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New pilih implementasi dari cfg.NotifyDriver: "smtp" atau "log" (default)
+func New(cfg *config.Config) (Notifier, error) {
+	switch cfg.NotifyDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" || cfg.MailFrom == "" {
+			return nil, fmt.Errorf("notify: SMTP_HOST dan MAIL_FROM wajib diisi untuk driver smtp")
+		}
+		return NewSMTP(SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}), nil
+	case "", "log":
+		return NewLog(cfg.NotifyLogFile), nil
+	default:
+		return nil, fmt.Errorf("notify: driver tidak dikenal %q", cfg.NotifyDriver)
+	}
+}
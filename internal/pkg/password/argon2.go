@@ -0,0 +1,102 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// Argon2id hasher argon2id dengan encoding PHC: $argon2id$v=19$m=<KiB>,t=<iterasi>,p=<thread>$<salt>$<hash>
+type Argon2id struct {
+	Memory  uint32 // KiB
+	Time    uint32
+	Threads uint8
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func (a *Argon2id) params() (uint32, uint32, uint8) {
+	m, t, p := a.Memory, a.Time, a.Threads
+	if m == 0 {
+		m = 64 * 1024
+	}
+	if t == 0 {
+		t = 3
+	}
+	if p == 0 {
+		p = 2
+	}
+	return m, t, p
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	m, t, p := a.params()
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, t, m, p, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, m, t, p,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a *Argon2id) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a *Argon2id) Verify(encoded, password string) (bool, error) {
+	ap, err := decodeArgon2(encoded)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), ap.salt, ap.time, ap.memory, ap.threads, uint32(len(ap.key)))
+	return subtle.ConstantTimeCompare(key, ap.key) == 1, nil
+}
+
+func (a *Argon2id) Outdated(encoded string) bool {
+	ap, err := decodeArgon2(encoded)
+	if err != nil {
+		return true
+	}
+	m, t, p := a.params()
+	return ap.memory < m || ap.time < t || ap.threads < p
+}
+
+func decodeArgon2(encoded string) (argon2Params, error) {
+	var ap argon2Params
+	parts := strings.Split(encoded, "$")
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return ap, ErrUnknownFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return ap, fmt.Errorf("password: versi argon2 tidak didukung")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &ap.memory, &ap.time, &ap.threads); err != nil {
+		return ap, fmt.Errorf("password: parameter argon2 tidak valid")
+	}
+	var err error
+	if ap.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return ap, fmt.Errorf("password: salt argon2 tidak valid")
+	}
+	if ap.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(ap.key) == 0 {
+		return ap, fmt.Errorf("password: hash argon2 tidak valid")
+	}
+	return ap, nil
+}
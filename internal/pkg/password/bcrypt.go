@@ -0,0 +1,47 @@
+package password
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Bcrypt hasher bcrypt; cost tersimpan di dalam hash
+type Bcrypt struct {
+	Cost int
+}
+
+func (b *Bcrypt) cost() int {
+	if b.Cost < bcrypt.MinCost {
+		return bcrypt.DefaultCost
+	}
+	return b.Cost
+}
+
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.cost())
+	return string(hashed), err
+}
+
+func (b *Bcrypt) Recognizes(encoded string) bool {
+	for _, p := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(encoded, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Bcrypt) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *Bcrypt) Outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < b.cost()
+}
@@ -0,0 +1,99 @@
+// Package password hashing & verifikasi password dengan algoritma yang bisa diganti.
+// Setiap hash menyimpan algoritma dan parameternya sendiri (format bcrypt "$2a$cost$..." atau
+// PHC "$argon2id$v=19$m=..,t=..,p=..$salt$hash"), sehingga hash lama tetap bisa diverifikasi
+// dan di-upgrade saat user login.
+package password
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/qullDev/book_API/internal/config"
+)
+
+// ErrUnknownFormat nilai tersimpan bukan hash yang dikenali (mis. password plaintext lama)
+var ErrUnknownFormat = errors.New("password: format hash tidak dikenal")
+
+// Hasher satu algoritma hashing
+type Hasher interface {
+	// Hash buat hash baru dengan parameter saat ini
+	Hash(password string) (string, error)
+	// Recognizes true jika encoded dibuat oleh algoritma ini
+	Recognizes(encoded string) bool
+	// Verify cocokkan password dengan hash buatan algoritma ini
+	Verify(encoded, password string) (bool, error)
+	// Outdated true jika parameter pada hash lebih lemah dari parameter saat ini
+	Outdated(encoded string) bool
+}
+
+// Manager hash memakai algoritma utama dan tetap bisa memverifikasi algoritma lain
+type Manager struct {
+	preferred Hasher
+	all       []Hasher
+
+	dummyOnce sync.Once
+	dummy     string // hash pengganti untuk user tidak dikenal, dibuat saat pertama dipakai
+}
+
+// NewManager preferred dipakai untuk hash baru; others hanya untuk verifikasi hash lama
+func NewManager(preferred Hasher, others ...Hasher) *Manager {
+	return &Manager{preferred: preferred, all: append([]Hasher{preferred}, others...)}
+}
+
+// New susun Manager dari konfigurasi PASSWORD_HASHER, BCRYPT_COST dan ARGON2_*
+func New(cfg *config.Config) (*Manager, error) {
+	b := &Bcrypt{Cost: cfg.BcryptCost}
+	a := &Argon2id{Memory: cfg.Argon2Memory, Time: cfg.Argon2Time, Threads: cfg.Argon2Threads}
+	switch cfg.PasswordHasher {
+	case "", "bcrypt":
+		return NewManager(b, a), nil
+	case "argon2id":
+		return NewManager(a, b), nil
+	default:
+		return nil, fmt.Errorf("password: hasher tidak dikenal %q", cfg.PasswordHasher)
+	}
+}
+
+// Hash buat hash baru dengan algoritma utama
+func (m *Manager) Hash(password string) (string, error) {
+	return m.preferred.Hash(password)
+}
+
+// Verify cocokkan password. rehash true jika password benar tetapi hash perlu dibuat ulang
+// (algoritma bukan algoritma utama atau parameternya sudah usang).
+// Nilai yang bukan hash dikenal (termasuk "" untuk user tidak terdaftar) selalu ditolak dengan
+// ErrUnknownFormat, setelah tetap memverifikasi hash pengganti agar waktu responsnya sama.
+func (m *Manager) Verify(encoded, password string) (ok, rehash bool, err error) {
+	h := m.find(encoded)
+	if h == nil {
+		m.preferred.Verify(m.dummyHash(), password)
+		return false, false, ErrUnknownFormat
+	}
+	ok, err = h.Verify(encoded, password)
+	if err != nil || !ok {
+		return false, false, err
+	}
+	return true, h != m.preferred || h.Outdated(encoded), nil
+}
+
+// IsHash true jika encoded berformat hash yang dikenali salah satu algoritma
+func (m *Manager) IsHash(encoded string) bool {
+	return m.find(encoded) != nil
+}
+
+func (m *Manager) dummyHash() string {
+	m.dummyOnce.Do(func() {
+		m.dummy, _ = m.preferred.Hash("book-api dummy password")
+	})
+	return m.dummy
+}
+
+func (m *Manager) find(encoded string) Hasher {
+	for _, h := range m.all {
+		if h.Recognizes(encoded) {
+			return h
+		}
+	}
+	return nil
+}
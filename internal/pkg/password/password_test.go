@@ -0,0 +1,150 @@
+package password
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// parameter rendah agar test cepat
+func fastBcrypt() *Bcrypt   { return &Bcrypt{Cost: bcrypt.MinCost} }
+func fastArgon2() *Argon2id { return &Argon2id{Memory: 1024, Time: 1, Threads: 1} }
+
+func TestHasherRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		hasher Hasher
+	}{
+		{"bcrypt", fastBcrypt()},
+		{"argon2id", fastArgon2()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.hasher.Hash("rahasia123")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.hasher.Recognizes(encoded) {
+				t.Fatalf("hasher does not recognize its own hash %q", encoded)
+			}
+			if ok, err := tt.hasher.Verify(encoded, "rahasia123"); err != nil || !ok {
+				t.Fatalf("Verify(correct) = %v, %v", ok, err)
+			}
+			if ok, err := tt.hasher.Verify(encoded, "salah"); err != nil || ok {
+				t.Fatalf("Verify(wrong) = %v, %v", ok, err)
+			}
+			if tt.hasher.Outdated(encoded) {
+				t.Fatal("fresh hash reported as outdated")
+			}
+			again, _ := tt.hasher.Hash("rahasia123")
+			if again == encoded {
+				t.Fatal("hashes are not salted")
+			}
+		})
+	}
+}
+
+func TestOutdated(t *testing.T) {
+	weakB, _ := fastBcrypt().Hash("pw")
+	weakA, _ := fastArgon2().Hash("pw")
+
+	tests := []struct {
+		name    string
+		hasher  Hasher
+		encoded string
+		want    bool
+	}{
+		{"bcrypt same cost", fastBcrypt(), weakB, false},
+		{"bcrypt higher cost", &Bcrypt{Cost: bcrypt.MinCost + 1}, weakB, true},
+		{"argon2 same params", fastArgon2(), weakA, false},
+		{"argon2 more memory", &Argon2id{Memory: 2048, Time: 1, Threads: 1}, weakA, true},
+		{"argon2 more time", &Argon2id{Memory: 1024, Time: 2, Threads: 1}, weakA, true},
+		{"argon2 more threads", &Argon2id{Memory: 1024, Time: 1, Threads: 2}, weakA, true},
+		{"argon2 malformed", fastArgon2(), "$argon2id$rusak", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.Outdated(tt.encoded); got != tt.want {
+				t.Errorf("Outdated = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerVerify(t *testing.T) {
+	bcryptHash, _ := fastBcrypt().Hash("rahasia123")
+	argonHash, _ := fastArgon2().Hash("rahasia123")
+
+	bcryptFirst := NewManager(fastBcrypt(), fastArgon2())
+	argonFirst := NewManager(fastArgon2(), fastBcrypt())
+	strongerBcrypt := NewManager(&Bcrypt{Cost: bcrypt.MinCost + 1}, fastArgon2())
+
+	tests := []struct {
+		name       string
+		m          *Manager
+		encoded    string
+		password   string
+		wantOK     bool
+		wantRehash bool
+		wantErr    error
+	}{
+		{"preferred bcrypt", bcryptFirst, bcryptHash, "rahasia123", true, false, nil},
+		{"preferred argon2", argonFirst, argonHash, "rahasia123", true, false, nil},
+		{"argon2 hash, bcrypt preferred", bcryptFirst, argonHash, "rahasia123", true, true, nil},
+		{"bcrypt hash, argon2 preferred", argonFirst, bcryptHash, "rahasia123", true, true, nil},
+		{"bcrypt cost raised", strongerBcrypt, bcryptHash, "rahasia123", true, true, nil},
+		{"wrong password", argonFirst, bcryptHash, "salah", false, false, nil},
+		{"plaintext", bcryptFirst, "rahasia123", "rahasia123", false, false, ErrUnknownFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash, err := tt.m.Verify(tt.encoded, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || rehash != tt.wantRehash {
+				t.Errorf("Verify = (%v, %v), want (%v, %v)", ok, rehash, tt.wantOK, tt.wantRehash)
+			}
+		})
+	}
+}
+
+func TestManagerHashUsesPreferred(t *testing.T) {
+	m := NewManager(fastArgon2(), fastBcrypt())
+	encoded, err := m.Hash("rahasia123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fastArgon2().Recognizes(encoded) {
+		t.Fatalf("hash %q not made by preferred hasher", encoded)
+	}
+	if !m.IsHash(encoded) || m.IsHash("rahasia123") {
+		t.Fatal("IsHash mismatch")
+	}
+}
+
+// countingHasher hitung pemanggilan Verify
+type countingHasher struct {
+	Hasher
+	verifies int
+}
+
+func (c *countingHasher) Verify(encoded, password string) (bool, error) {
+	c.verifies++
+	return c.Hasher.Verify(encoded, password)
+}
+
+func TestManagerVerifyUnknownHashesDummy(t *testing.T) {
+	h := &countingHasher{Hasher: fastBcrypt()}
+	m := NewManager(h)
+	for _, encoded := range []string{"", "rahasia123"} {
+		ok, _, err := m.Verify(encoded, "rahasia123")
+		if ok || !errors.Is(err, ErrUnknownFormat) {
+			t.Fatalf("Verify(%q) = (%v, %v), want ErrUnknownFormat", encoded, ok, err)
+		}
+	}
+	if h.verifies != 2 {
+		t.Fatalf("dummy hash verified %d times, want 2", h.verifies)
+	}
+}
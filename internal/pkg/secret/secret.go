@@ -0,0 +1,71 @@
+// Package secret enkripsi data sensitif yang disimpan di database (private key JWT, secret MFA)
+// dengan key encryption key (KEK) dari konfigurasi. Ciphertext AES-256-GCM disimpan sebagai
+// "enc:v1:<base64(nonce||ciphertext)>" sehingga nilai lama yang masih plaintext bisa dikenali.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+const prefix = "enc:v1:"
+
+// ErrInvalid ciphertext rusak, dibuat dengan KEK lain, atau bukan hasil Seal
+var ErrInvalid = errors.New("secret: ciphertext tidak valid")
+
+// Box enkripsi/dekripsi dengan satu KEK
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New buat Box dari KEK 32 byte (AES-256)
+func New(kek []byte) (*Box, error) {
+	if len(kek) != 32 {
+		return nil, errors.New("secret: KEK harus 32 byte")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Sealed true jika s hasil Seal (bukan plaintext lama)
+func Sealed(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+// Seal enkripsi plaintext dengan nonce acak
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(out), nil
+}
+
+// Open dekripsi hasil Seal
+func (b *Box) Open(s string) (string, error) {
+	if !Sealed(s) {
+		return "", ErrInvalid
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(s, prefix))
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrInvalid
+	}
+	n := b.aead.NonceSize()
+	plain, err := b.aead.Open(nil, raw[:n], raw[n:], nil)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,62 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	box, err := New(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := box.Seal("rahasia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Sealed(sealed) || strings.Contains(sealed, "rahasia") {
+		t.Fatalf("unexpected ciphertext %q", sealed)
+	}
+	again, _ := box.Seal("rahasia")
+	if again == sealed {
+		t.Fatal("nonce reused")
+	}
+	plain, err := box.Open(sealed)
+	if err != nil || plain != "rahasia" {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+
+	other, _ := New(bytes.Repeat([]byte{2}, 32))
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if tampered == sealed {
+		tampered = sealed[:len(sealed)-2] + "BB"
+	}
+	tests := []struct {
+		name string
+		box  *Box
+		in   string
+	}{
+		{"plaintext", box, "rahasia"},
+		{"different KEK", other, sealed},
+		{"tampered", box, tampered},
+		{"bad base64", box, prefix + "!!!"},
+		{"too short", box, prefix + "AAAA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.box.Open(tt.in); !errors.Is(err, ErrInvalid) {
+				t.Fatalf("err = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestNewKeyLength(t *testing.T) {
+	for _, n := range []int{0, 16, 31, 33} {
+		if _, err := New(make([]byte, n)); err == nil {
+			t.Errorf("New accepted a %d byte KEK", n)
+		}
+	}
+}
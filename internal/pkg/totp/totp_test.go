@@ -0,0 +1,85 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// secret RFC 6238 lampiran B untuk SHA1 ("12345678901234567890") dalam base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// nilai RFC 8 digit, diambil 6 digit terakhir
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Fatal("expected error for invalid secret")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+	prev, _ := Code(rfcSecret, step-1)
+	next, _ := Code(rfcSecret, step+1)
+	far, _ := Code(rfcSecret, step-3)
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current", "050471", 1, step, true},
+		{"current with spaces", " 050471 ", 0, step, true},
+		{"previous within skew", prev, 1, step - 1, true},
+		{"next within skew", next, 1, step + 1, true},
+		{"previous without skew", prev, 0, 0, false},
+		{"outside skew", far, 1, 0, false},
+		{"wrong code", "000000", 1, 0, false},
+		{"wrong length", "05047", 1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || got != tt.wantStep {
+				t.Errorf("Validate = (%d, %v), want (%d, %v)", got, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	s, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Code(s, 1); err != nil {
+		t.Fatalf("generated secret not usable: %v", err)
+	}
+	if other, _ := GenerateSecret(); other == s {
+		t.Fatal("secrets not random")
+	}
+}